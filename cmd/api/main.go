@@ -3,26 +3,44 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	http2 "github.com/compiai/engine/internal/api/http"
 	"github.com/compiai/engine/internal/core/domain/agent/stat_analyzer"
+	"github.com/compiai/engine/pkg/jwk"
 	"github.com/compiai/engine/pkg/llm"
 	"github.com/compiai/engine/pkg/llm/claude"
 	"github.com/compiai/engine/pkg/llm/openai"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	_ "github.com/lib/pq"
 	"gopkg.in/yaml.v2"
 
+	"github.com/compiai/engine/internal/core/domain/access"
+	domainaccount "github.com/compiai/engine/internal/core/domain/account"
 	promptloader "github.com/compiai/engine/internal/core/domain/agent/stat_analyzer/prompt"
+	domaincoaching "github.com/compiai/engine/internal/core/domain/coaching"
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/game/cs2"
+	"github.com/compiai/engine/internal/core/domain/game/dota2"
+	"github.com/compiai/engine/internal/core/domain/game/league"
+	"github.com/compiai/engine/internal/core/domain/game/valorant"
+	domainmatch "github.com/compiai/engine/internal/core/domain/match"
+	domainquota "github.com/compiai/engine/internal/core/domain/quota"
+	domainteam "github.com/compiai/engine/internal/core/domain/team"
+	domainusage "github.com/compiai/engine/internal/core/domain/usage"
 	domainuser "github.com/compiai/engine/internal/core/domain/user"
 	"github.com/compiai/engine/internal/core/ext/storage"
+	"github.com/compiai/engine/internal/core/ext/storage/memory"
 	"log/slog"
 )
 
@@ -32,9 +50,15 @@ type AppConfig struct {
 		Version string `yaml:"version"`
 
 		Database struct {
+			// Driver selects the storage backend: postgres (default) or
+			// memory, which keeps everything in-process for local development.
+			Driver   string `yaml:"driver"`
 			Postgres struct {
 				Addr string `yaml:"addr"`
-				Auth struct {
+				Name string `yaml:"name"`
+				// AutoMigrate applies pending schema migrations at startup.
+				AutoMigrate bool `yaml:"autoMigrate"`
+				Auth        struct {
 					Username   string `yaml:"username"`
 					Password   string `yaml:"password"`
 					TLSEnabled bool   `yaml:"tlsEnabled"`
@@ -43,10 +67,19 @@ type AppConfig struct {
 		} `yaml:"database"`
 
 		Clients struct {
-			Claude claude.Config `yaml:"claude"`
-			OpenAI openai.Config `yaml:"openai"`
+			// Provider selects the LLM backend: openai or claude.
+			Provider string `yaml:"provider"`
+			// Fallbacks are tried in order when Provider fails with a retryable error.
+			Fallbacks []string      `yaml:"fallbacks"`
+			Claude    claude.Config `yaml:"claude"`
+			OpenAI    openai.Config `yaml:"openai"`
+			// Pricing maps model names to USD prices per million tokens.
+			Pricing llm.PriceTable `yaml:"pricing"`
 		} `yaml:"clients"`
 
+		// Quota holds the default per-user limits; admin overrides live in Postgres.
+		Quota domainquota.Limits `yaml:"quota"`
+
 		Server struct {
 			Public struct {
 				Addr    string        `yaml:"addr"`
@@ -55,8 +88,13 @@ type AppConfig struct {
 		} `yaml:"server"`
 
 		Auth struct {
+			// PrivateKey and PublicKey are file:// URLs of the PEM encoded
+			// RSA or Ed25519 key pair used to sign tokens.
 			PrivateKey string `yaml:"privateKey"`
 			PublicKey  string `yaml:"publicKey"`
+			// VerificationKeys are file:// URLs of retired public keys still
+			// accepted for verification during a key rotation.
+			VerificationKeys []string `yaml:"verificationKeys"`
 		} `yaml:"auth"`
 	} `yaml:"application"`
 }
@@ -74,8 +112,209 @@ func loadConfig(path string) (*AppConfig, error) {
 	return &cfg, nil
 }
 
+// newLLMProviders registers a factory for every supported LLM provider.
+// Only the selected provider is constructed, so only its config is validated.
+func newLLMProviders(logger *slog.Logger, cfg *AppConfig) *llm.Registry {
+	providers := llm.NewRegistry()
+	providers.Register("openai", func() (llm.Streamer, error) {
+		if err := cfg.Application.Clients.OpenAI.Validate(); err != nil {
+			return nil, err
+		}
+		return openai.NewClient(logger, cfg.Application.Clients.OpenAI), nil
+	})
+	providers.Register("claude", func() (llm.Streamer, error) {
+		if err := cfg.Application.Clients.Claude.Validate(); err != nil {
+			return nil, err
+		}
+		return claude.NewClaudeClient(logger, cfg.Application.Clients.Claude), nil
+	})
+	return providers
+}
+
+// newLLMStreamer builds the primary provider, wrapped in a Fallback when
+// fallback providers are configured.
+func newLLMStreamer(logger *slog.Logger, cfg *AppConfig) (llm.Streamer, error) {
+	providers := newLLMProviders(logger, cfg)
+	names := append([]string{cfg.Application.Clients.Provider}, cfg.Application.Clients.Fallbacks...)
+	chain := make([]llm.NamedStreamer, 0, len(names))
+	for _, name := range names {
+		streamer, err := providers.New(name)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, llm.NamedStreamer{Name: name, Streamer: streamer})
+	}
+	if len(chain) == 1 {
+		return chain[0].Streamer, nil
+	}
+	return llm.NewFallback(logger, chain...), nil
+}
+
+// loadTokenKeys loads the token signing key pair and any retired
+// verification keys from the auth config.
+func loadTokenKeys(cfg *AppConfig) (*jwk.Set, error) {
+	priv, err := jwk.LoadPrivateKey(cfg.Application.Auth.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("auth.privateKey: %w", err)
+	}
+	pub, err := jwk.LoadPublicKey(cfg.Application.Auth.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("auth.publicKey: %w", err)
+	}
+	signing, err := jwk.NewKey(pub, priv)
+	if err != nil {
+		return nil, fmt.Errorf("auth signing key: %w", err)
+	}
+	verification := make([]jwk.Key, 0, len(cfg.Application.Auth.VerificationKeys))
+	for i, keyURL := range cfg.Application.Auth.VerificationKeys {
+		pub, err := jwk.LoadPublicKey(keyURL)
+		if err != nil {
+			return nil, fmt.Errorf("auth.verificationKeys[%d]: %w", i, err)
+		}
+		key, err := jwk.NewKey(pub, nil)
+		if err != nil {
+			return nil, fmt.Errorf("auth.verificationKeys[%d]: %w", i, err)
+		}
+		verification = append(verification, key)
+	}
+	return jwk.NewSet(signing, verification...)
+}
+
+// storages groups the storage implementations behind the domain services.
+type storages struct {
+	users      domainuser.Storage
+	tokens     domainuser.TokenStorage
+	challenges domainuser.ChallengeStorage
+	coaching   domaincoaching.Storage
+	matches    domainmatch.Storage
+	usage      domainusage.Storage
+	quotas     domainquota.Storage
+	teams      domainteam.Storage
+}
+
+func newPostgresStorages(db *sql.DB) storages {
+	return storages{
+		users:      storage.NewPostgresStorage(db),
+		tokens:     storage.NewPostgresTokenStorage(db),
+		challenges: storage.NewPostgresChallengeStorage(db),
+		coaching:   storage.NewPostgresCoachingStorage(db),
+		matches:    storage.NewPostgresMatchStorage(db),
+		usage:      storage.NewPostgresUsageStorage(db),
+		quotas:     storage.NewPostgresQuotaStorage(db),
+		teams:      storage.NewPostgresTeamStorage(db),
+	}
+}
+
+func newMemoryStorages() storages {
+	users := memory.NewUserStorage()
+	tokens := memory.NewTokenStorage()
+	coaching := memory.NewCoachingStorage()
+	matches := memory.NewMatchStorage()
+	usage := memory.NewUsageStorage()
+	quotas := memory.NewQuotaStorage()
+	teams := memory.NewTeamStorage()
+	// deleting a user cascades like the foreign keys of the postgres schema
+	users.CascadeTo(tokens, coaching, matches, usage, quotas, teams)
+	return storages{
+		users:      users,
+		tokens:     tokens,
+		challenges: memory.NewChallengeStorage(),
+		coaching:   coaching,
+		matches:    matches,
+		usage:      usage,
+		quotas:     quotas,
+		teams:      teams,
+	}
+}
+
+// openPostgres connects to the configured database and checks that it is reachable.
+func openPostgres(cfg *AppConfig) (*sql.DB, error) {
+	sslMode := "disable"
+	if cfg.Application.Database.Postgres.Auth.TLSEnabled {
+		sslMode = "require"
+	}
+	dsn := (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Application.Database.Postgres.Auth.Username, cfg.Application.Database.Postgres.Auth.Password),
+		Host:     cfg.Application.Database.Postgres.Addr,
+		Path:     "/" + cfg.Application.Database.Postgres.Name,
+		RawQuery: "sslmode=" + sslMode,
+	}).String()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping: %w", err)
+	}
+	return db, nil
+}
+
+// runMigrate executes the migrate subcommand: up (default), down [steps]
+// or version.
+func runMigrate(logger *slog.Logger, db *sql.DB, args []string) error {
+	migrator, err := storage.NewMigrator(logger, db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+	var version int
+	switch cmd {
+	case "up":
+		version, err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("migrate down: invalid steps %q", args[1])
+			}
+		}
+		version, err = migrator.Down(ctx, steps)
+	case "version":
+		version, err = migrator.Version(ctx)
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down [steps] or version", cmd)
+	}
+	if err != nil {
+		return err
+	}
+	migrations := migrator.Migrations()
+	logger.Info("schema version", "version", version, "latest", migrations[len(migrations)-1].Version)
+	return nil
+}
+
+// runGrantRole executes the grant-role subcommand: it adds a role to the user
+// with the given username. It is how the first platform admin is created,
+// since roles are otherwise only managed by platform admins.
+func runGrantRole(logger *slog.Logger, userService domainuser.Service, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: grant-role <username> <role>")
+	}
+	username, role := args[0], domainuser.Role(args[1])
+	if !role.Valid() {
+		return fmt.Errorf("%w: %q", domainuser.ErrInvalidRole, role)
+	}
+	ctx := context.Background()
+	u, err := userService.FindOne(ctx, domainuser.SingleFilter{Username: &username})
+	if err != nil {
+		return err
+	}
+	if slices.Contains(u.Roles, role) {
+		logger.Info("user already has the role", "userId", u.ID, "role", role)
+		return nil
+	}
+	return userService.SetRoles(ctx, u.ID, append(u.Roles, role))
+}
+
 func main() {
-	// Parse config file path
+	// Parse config file path; a trailing "migrate" or "grant-role" runs that
+	// subcommand instead of the server
 	configPath := flag.String("config", "config.yaml", "path to YAML config file")
 	flag.Parse()
 
@@ -89,40 +328,78 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
 	logger.Info("starting application", "version", cfg.Application.Version)
 
-	// Build Postgres DSN
-	sslMode := "disable"
-	if cfg.Application.Database.Postgres.Auth.TLSEnabled {
-		sslMode = "require"
+	// Initialize the storages selected by database.driver
+	var stores storages
+	switch cfg.Application.Database.Driver {
+	case "", "postgres":
+		db, err := openPostgres(cfg)
+		if err != nil {
+			logger.Error("database connection failed", "err", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		// Apply schema migrations
+		if flag.Arg(0) == "migrate" {
+			if err := runMigrate(logger, db, flag.Args()[1:]); err != nil {
+				logger.Error("migrate failed", "err", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if cfg.Application.Database.Postgres.AutoMigrate {
+			if err := runMigrate(logger, db, []string{"up"}); err != nil {
+				logger.Error("migrate failed", "err", err)
+				os.Exit(1)
+			}
+		}
+		stores = newPostgresStorages(db)
+	case "memory":
+		if flag.Arg(0) == "migrate" || flag.Arg(0) == "grant-role" {
+			logger.Error("subcommand requires the postgres database driver", "command", flag.Arg(0))
+			os.Exit(1)
+		}
+		logger.Warn("using in-memory storage, all data is lost on restart")
+		stores = newMemoryStorages()
+	default:
+		logger.Error("unknown database driver, expected postgres or memory", "driver", cfg.Application.Database.Driver)
+		os.Exit(1)
 	}
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/?sslmode=%s",
-		cfg.Application.Database.Postgres.Auth.Username,
-		cfg.Application.Database.Postgres.Auth.Password,
-		cfg.Application.Database.Postgres.Addr,
-		sslMode,
-	)
 
-	// Connect to Postgres
-	db, err := sql.Open("postgres", dsn)
+	// Register per-title game plugins
+	gamePlugins := game.NewRegistry(valorant.New(), league.New(), dota2.New(), cs2.New())
+
+	// Initialize domain services
+	userService := domainuser.NewService(logger, stores.users)
+	if flag.Arg(0) == "grant-role" {
+		if err := runGrantRole(logger, userService, flag.Args()[1:]); err != nil {
+			logger.Error("grant-role failed", "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+	tokenKeys, err := loadTokenKeys(cfg)
 	if err != nil {
-		logger.Error("database connection failed", "err", err)
+		logger.Error("auth init failed", "err", err)
 		os.Exit(1)
 	}
-	defer db.Close()
-	if err := db.PingContext(context.Background()); err != nil {
-		logger.Error("database ping failed", "err", err)
+	coachingService := domaincoaching.NewService(logger, stores.coaching, userService)
+	accessPolicy := access.NewPolicy(logger, coachingService)
+	authService := domainuser.NewAuthService(logger, stores.users, stores.tokens, stores.challenges, tokenKeys)
+	matchService := domainmatch.NewService(logger, stores.matches, gamePlugins)
+	usageService := domainusage.NewService(logger, stores.usage, cfg.Application.Clients.Pricing)
+	quotaService := domainquota.NewService(logger, stores.quotas, usageService, cfg.Application.Quota)
+	teamService := domainteam.NewService(logger, stores.teams, userService, gamePlugins, coachingService)
+	accountService := domainaccount.NewService(logger, userService, matchService, usageService, coachingService, teamService)
+
+	// Initialize the configured LLM provider and its fallbacks
+	llmStreamer, err := newLLMStreamer(logger, cfg)
+	if err != nil {
+		logger.Error("LLM provider init failed", "err", err)
 		os.Exit(1)
 	}
-
-	// Initialize storage and domain services
-	userStorage := storage.NewPostgresStorage(db)
-	userService := domainuser.NewService(logger, userStorage)
-
-	// Initialize LLM clients
-	openaiClient := openai.NewClient(logger, cfg.Application.Clients.OpenAI)
-	// claudeClient := claude.NewClaudeClient(logger, cfg.Application.Clients.Claude)
-
-	// Choose streamer; here using OpenAI but could swap to Claude
-	var llmStreamer llm.Streamer = openaiClient
+	logger.Info("LLM provider selected",
+		"provider", cfg.Application.Clients.Provider, "fallbacks", cfg.Application.Clients.Fallbacks)
 
 	// Initialize prompt loader
 	pl, err := promptloader.NewPromptLoader()
@@ -132,11 +409,11 @@ func main() {
 	}
 
 	// Initialize agent
-	statAgent := stat_analyzer.NewAgent(logger, llmStreamer, *pl, userService)
+	statAgent := stat_analyzer.NewAgent(logger, llmStreamer, *pl, userService, matchService, gamePlugins, usageService, quotaService, teamService)
 
 	// Setup HTTP router
 	r := chi.NewRouter()
-	http2.RegisterRoutes(r, statAgent, logger)
+	http2.RegisterRoutes(r, authService, tokenKeys, userService, accountService, accessPolicy, coachingService, teamService, statAgent, matchService, quotaService, logger)
 
 	// Start HTTP server
 	srv := &http.Server{
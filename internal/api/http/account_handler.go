@@ -0,0 +1,311 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"log/slog"
+
+	"github.com/compiai/engine/internal/core/domain/account"
+	"github.com/compiai/engine/internal/core/domain/user"
+)
+
+// UpdateUserRequest changes a user's profile; omitted fields are left as is.
+type UpdateUserRequest struct {
+	Username *string   `json:"username"`
+	Games    *[]string `json:"games"`
+}
+
+// ChangePasswordRequest replaces the caller's password. CurrentPassword is
+// empty for wallet users setting their first password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// UserExportResponse is the data dump of a user.
+type UserExportResponse struct {
+	User        UserResponse          `json:"user"`
+	Matches     []MatchRecordResponse `json:"matches"`
+	Usage       []UsageRecordResponse `json:"usage"`
+	Coaches     []GrantResponse       `json:"coaches"`
+	Players     []GrantResponse       `json:"players"`
+	Invitations []InvitationResponse  `json:"invitations"`
+	Teams       []TeamResponse        `json:"teams"`
+	ExportedAt  time.Time             `json:"exportedAt"`
+}
+
+// UsageRecordResponse is the LLM usage of a user for one day and model.
+type UsageRecordResponse struct {
+	Day              time.Time `json:"day"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Requests         int       `json:"requests"`
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	CostUSD          float64   `json:"costUsd"`
+}
+
+func newUserExportResponse(e account.Export) UserExportResponse {
+	res := UserExportResponse{
+		User:        newUserResponse(e.User),
+		Matches:     make([]MatchRecordResponse, 0, len(e.Matches)),
+		Usage:       make([]UsageRecordResponse, 0, len(e.Usage)),
+		Coaches:     make([]GrantResponse, 0, len(e.Coaches)),
+		Players:     make([]GrantResponse, 0, len(e.Players)),
+		Invitations: make([]InvitationResponse, 0, len(e.Invitations)),
+		Teams:       make([]TeamResponse, 0, len(e.Teams)),
+		ExportedAt:  e.ExportedAt,
+	}
+	for _, m := range e.Matches {
+		res.Matches = append(res.Matches, newMatchRecordResponse(m))
+	}
+	for _, r := range e.Usage {
+		res.Usage = append(res.Usage, UsageRecordResponse{
+			Day:              r.Day,
+			Provider:         r.Provider,
+			Model:            r.Model,
+			Requests:         r.Requests,
+			PromptTokens:     r.PromptTokens,
+			CompletionTokens: r.CompletionTokens,
+			CostUSD:          r.CostUSD,
+		})
+	}
+	for _, g := range e.Coaches {
+		res.Coaches = append(res.Coaches, newGrantResponse(g))
+	}
+	for _, g := range e.Players {
+		res.Players = append(res.Players, newGrantResponse(g))
+	}
+	for _, inv := range e.Invitations {
+		res.Invitations = append(res.Invitations, newInvitationResponse(inv))
+	}
+	for _, t := range e.Teams {
+		res.Teams = append(res.Teams, newTeamResponse(t))
+	}
+	return res
+}
+
+// registerAccountRoutes mounts the endpoints users manage their own account
+// with onto an authenticated router. Platform admins may act on any user,
+// except for changing passwords.
+func registerAccountRoutes(
+	r chi.Router,
+	userService user.Service,
+	authService user.AuthService,
+	accountService account.Service,
+	logger *slog.Logger,
+) {
+	r.Route("/users/{id}", func(r chi.Router) {
+		r.Patch("/", makeUpdateUserHandler(userService, logger))
+		r.Delete("/", makeDeleteUserHandler(accountService, logger))
+		r.Get("/export", makeExportUserHandler(accountService, logger))
+		r.Put("/password", makeChangePasswordHandler(userService, authService, logger))
+		r.Put("/wallet", makeLinkWalletHandler(authService, logger))
+		r.Delete("/wallet", makeUnlinkWalletHandler(authService, logger))
+	})
+}
+
+// makeUpdateUserHandler changes the username or games of a user.
+func makeUpdateUserHandler(userService user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := accountUserID(w, req, logger)
+		if !ok {
+			return
+		}
+		var reqModel UpdateUserRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		u, err := userService.Update(req.Context(), id, user.UserUpdate{
+			Username: reqModel.Username,
+			Games:    reqModel.Games,
+		})
+		switch {
+		case errors.Is(err, user.ErrInvalidUser):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("update user failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not update user")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newUserResponse(u))
+	}
+}
+
+// makeLinkWalletHandler links a wallet to a user once its owner signed a
+// challenge from GET /auth/wallet/challenge.
+func makeLinkWalletHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := accountUserID(w, req, logger)
+		if !ok {
+			return
+		}
+		var reqModel WalletVerifyRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		u, err := authService.LinkWallet(req.Context(), id, user.WalletSignature{
+			Wallet:    reqModel.Wallet,
+			Nonce:     reqModel.Nonce,
+			Signature: reqModel.Signature,
+		})
+		switch {
+		case errors.Is(err, user.ErrInvalidWallet):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, user.ErrInvalidChallenge), errors.Is(err, user.ErrInvalidSignature):
+			writeError(w, logger, http.StatusForbidden, err.Error())
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("link wallet failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not link wallet")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newUserResponse(u))
+	}
+}
+
+// makeUnlinkWalletHandler removes the wallet of a user who has a password.
+func makeUnlinkWalletHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := accountUserID(w, req, logger)
+		if !ok {
+			return
+		}
+		u, err := authService.UnlinkWallet(req.Context(), id)
+		switch {
+		case errors.Is(err, user.ErrInvalidUser):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("unlink wallet failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not unlink wallet")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newUserResponse(u))
+	}
+}
+
+// makeChangePasswordHandler lets users change their own password. Every
+// session of the user is revoked afterwards, so they log in again.
+func makeChangePasswordHandler(userService user.Service, authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, err := uuid.Parse(chi.URLParam(req, "id"))
+		if err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		if callerID, _ := UserIDFromContext(req.Context()); callerID != id {
+			writeError(w, logger, http.StatusForbidden, "users can only change their own password")
+			return
+		}
+		var reqModel ChangePasswordRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		err = userService.ChangePassword(req.Context(), id, reqModel.CurrentPassword, reqModel.NewPassword)
+		switch {
+		case errors.Is(err, user.ErrInvalidUser):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, user.ErrInvalidCredentials):
+			writeError(w, logger, http.StatusForbidden, "current password is incorrect")
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("change password failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not change password")
+			return
+		}
+		if err := authService.LogoutAll(req.Context(), id); err != nil {
+			// the password did change; stale sessions expire on their own
+			logger.Error("revoke sessions after password change failed", "err", err, "userId", id)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// makeExportUserHandler hands out everything stored about a user as a
+// downloadable JSON document.
+func makeExportUserHandler(accountService account.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := accountUserID(w, req, logger)
+		if !ok {
+			return
+		}
+		export, err := accountService.Export(req.Context(), id)
+		switch {
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("export user failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not export user")
+			return
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="user-`+id.String()+`.json"`)
+		writeJSON(w, logger, http.StatusOK, newUserExportResponse(export))
+	}
+}
+
+// makeDeleteUserHandler erases a user and everything referencing them,
+// answering with the data export taken right before.
+func makeDeleteUserHandler(accountService account.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := accountUserID(w, req, logger)
+		if !ok {
+			return
+		}
+		export, err := accountService.Delete(req.Context(), id)
+		switch {
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("delete user failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not delete user")
+			return
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="user-`+id.String()+`.json"`)
+		writeJSON(w, logger, http.StatusOK, newUserExportResponse(export))
+	}
+}
+
+// accountUserID parses the user ID in the path and checks that the caller
+// is that user or a platform admin, writing the error response if not.
+func accountUserID(w http.ResponseWriter, req *http.Request, logger *slog.Logger) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		writeError(w, logger, http.StatusBadRequest, "invalid user id")
+		return uuid.Nil, false
+	}
+	principal, _ := PrincipalFromContext(req.Context())
+	if principal.UserID != id && !principal.HasRole(user.RolePlatformAdmin) {
+		writeError(w, logger, http.StatusForbidden, "not allowed to manage this user")
+		return uuid.Nil, false
+	}
+	return id, true
+}
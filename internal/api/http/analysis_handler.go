@@ -3,105 +3,220 @@ package http
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"log/slog"
 
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/account"
 	"github.com/compiai/engine/internal/core/domain/agent/stat_analyzer"
+	"github.com/compiai/engine/internal/core/domain/coaching"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/compiai/engine/internal/core/domain/quota"
+	"github.com/compiai/engine/internal/core/domain/team"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/compiai/engine/pkg/jwk"
+	"github.com/compiai/engine/pkg/llm"
 )
 
-// AnalysisRequest defines the payload for analysis.
+// AnalysisRequest optionally names the player to analyze; it defaults to
+// the authenticated user.
 type AnalysisRequest struct {
 	UserID uuid.UUID `json:"userId"`
 }
 
-// Validate checks required fields in AnalysisRequest.
-func (r *AnalysisRequest) Validate() error {
-	if r.UserID == uuid.Nil {
-		return errors.New("userId is required")
-	}
-	return nil
-}
-
 // AnalysisResponse is sent for each analysis segment.
 type AnalysisResponse struct {
 	ID      string `json:"id"`
+	Stage   string `json:"stage"`
 	Content string `json:"content"`
 	Error   string `json:"error,omitempty"`
 }
 
-// RegisterRoutes mounts stat analyzer endpoints onto the router.
-func RegisterRoutes(r chi.Router, agent stat_analyzer.Agent, logger *slog.Logger) {
-	r.Route("/analysis", func(r chi.Router) {
-		r.Post("/", makeAnalysisHandler(agent, logger))
+// QuotaExceededResponse is returned with 429 when a user ran out of quota.
+type QuotaExceededResponse struct {
+	Error   string    `json:"error"`
+	Limit   string    `json:"limit"`
+	Used    int       `json:"used"`
+	Max     int       `json:"max"`
+	ResetAt time.Time `json:"resetAt"`
+}
+
+// RegisterRoutes mounts the token and signup endpoints and the authenticated
+// stat analyzer, match ingestion, coaching and team endpoints onto the router.
+func RegisterRoutes(
+	r chi.Router,
+	authService user.AuthService,
+	keys *jwk.Set,
+	userService user.Service,
+	accountService account.Service,
+	policy access.Policy,
+	coachingService coaching.Service,
+	teamService team.Service,
+	agent stat_analyzer.Agent,
+	matchService match.Service,
+	quotaService quota.Service,
+	logger *slog.Logger,
+) {
+	registerAuthRoutes(r, authService, keys, logger)
+	registerUserRoutes(r, userService, logger)
+	r.Group(func(r chi.Router) {
+		r.Use(Authenticator(authService, logger))
+		r.Route("/analysis", func(r chi.Router) {
+			r.Post("/", makeAnalysisHandler(agent, policy, logger))
+		})
+		registerMatchRoutes(r, matchService, policy, logger)
+		registerCoachingRoutes(r, coachingService, logger)
+		registerTeamRoutes(r, teamService, policy, agent, logger)
+		registerAccountRoutes(r, userService, authService, accountService, logger)
+		registerAdminRoutes(r, userService, quotaService, logger)
 	})
 }
 
-// makeAnalysisHandler streams analysis via Server-Sent Events.
-func makeAnalysisHandler(agent stat_analyzer.Agent, logger *slog.Logger) http.HandlerFunc {
+// makeAnalysisHandler streams analysis via Server-Sent Events. Players analyze
+// themselves; coaches may name a player who granted them analysis access.
+func makeAnalysisHandler(agent stat_analyzer.Agent, policy access.Policy, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		// Decode and validate JSON request body.
+		principal, ok := PrincipalFromContext(req.Context())
+		if !ok {
+			writeError(w, logger, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+
+		// Decode the optional request body.
 		var reqModel AnalysisRequest
-		if err := json.NewDecoder(req.Body).Decode(&reqModel); err != nil {
+		if err := json.NewDecoder(req.Body).Decode(&reqModel); err != nil && err != io.EOF {
 			logger.Error("invalid request body", "err", err)
-			http.Error(w, "invalid request body", http.StatusBadRequest)
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
 			return
 		}
-		if err := reqModel.Validate(); err != nil {
-			logger.Error("validation error", "err", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		userID := reqModel.UserID
+		if userID == uuid.Nil {
+			userID = principal.UserID
+		}
+		err := policy.Authorize(req.Context(), principal, userID, access.ScopeRunAnalysis)
+		switch {
+		case errors.Is(err, access.ErrForbidden):
+			writeError(w, logger, http.StatusForbidden, "not allowed to analyze this user")
+			return
+		case err != nil:
+			logger.Error("authorization failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "analysis error")
 			return
 		}
 
-		// Set SSE headers.
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache, no-transform")
-		w.Header().Set("Connection", "keep-alive")
 		flusher, ok := w.(http.Flusher)
 		if !ok {
-			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			writeError(w, logger, http.StatusInternalServerError, "streaming unsupported")
 			return
 		}
 
 		// Initiate analysis stream.
 		ctx := req.Context()
-		stream, err := agent.BuildAnalysis(ctx, stat_analyzer.BuildAnalysisRequest{UserID: reqModel.UserID})
+		stream, err := agent.BuildAnalysis(ctx, stat_analyzer.BuildAnalysisRequest{UserID: userID, RequestedBy: principal.UserID})
 		if err != nil {
 			logger.Error("analysis build error", "err", err)
-			http.Error(w, "analysis error", http.StatusInternalServerError)
+			writeAnalysisError(w, logger, err)
 			return
 		}
 
-		// Stream SSE events.
-		for resp := range stream {
-			// Build and marshal response.
-			res := AnalysisResponse{ID: resp.ID, Content: resp.Content}
-			if resp.Error != nil {
-				res.Error = resp.Error.Error()
-			}
-			payload, err := json.Marshal(res)
-			if err != nil {
-				logger.Error("marshal response failed", "err", err)
-				continue
-			}
-
-			// Send SSE event.
-			w.Write([]byte("event: analysis\n"))
-			w.Write([]byte("data: "))
-			w.Write(payload)
-			w.Write([]byte("\n\n"))
-			flusher.Flush()
-
-			// Small delay to regulate stream.
-			time.Sleep(10 * time.Millisecond)
+		streamAnalysis(w, flusher, logger, stream)
+	}
+}
+
+// writeAnalysisError maps a failure to start an analysis to an HTTP status.
+func writeAnalysisError(w http.ResponseWriter, logger *slog.Logger, err error) {
+	var exceeded *quota.ExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		writeQuotaExceeded(w, logger, exceeded)
+	case errors.Is(err, llm.ErrRateLimited), errors.Is(err, llm.ErrOverloaded),
+		errors.Is(err, llm.ErrUnavailable), errors.Is(err, llm.ErrFirstTokenTimeout):
+		writeError(w, logger, http.StatusServiceUnavailable, "analysis temporarily unavailable, try again later")
+	case errors.Is(err, llm.ErrContextLength):
+		writeError(w, logger, http.StatusUnprocessableEntity, "too much match history to analyze at once")
+	case errors.Is(err, team.ErrTeamNotFound):
+		writeError(w, logger, http.StatusNotFound, "team not found")
+	case isUserError(err):
+		writeUserError(w, logger, err)
+	default:
+		writeError(w, logger, http.StatusInternalServerError, "analysis error")
+	}
+}
+
+// sseWriteTimeout bounds writing a single Server-Sent Event. A stream lasts
+// as long as the LLM takes, so the server's WriteTimeout is pushed back before
+// every event instead of cutting the stream off.
+const sseWriteTimeout = 10 * time.Second
+
+// streamAnalysis relays an analysis stream as Server-Sent Events named after
+// the pipeline stage, followed by a final end event.
+func streamAnalysis(w http.ResponseWriter, flusher http.Flusher, logger *slog.Logger, stream <-chan stat_analyzer.BuildAnalysisStreamResponse) {
+	// Set SSE headers.
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache, no-transform")
+	w.Header().Set("Connection", "keep-alive")
+
+	rc := http.NewResponseController(w)
+	extendDeadline := func() {
+		if err := rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logger.Warn("extend write deadline failed", "err", err)
+		}
+	}
+
+	// Stream SSE events.
+	for resp := range stream {
+		// Build and marshal response.
+		res := AnalysisResponse{ID: resp.ID, Stage: resp.Stage, Content: resp.Content}
+		if resp.Error != nil {
+			res.Error = resp.Error.Error()
+		}
+		payload, err := json.Marshal(res)
+		if err != nil {
+			logger.Error("marshal response failed", "err", err)
+			continue
 		}
 
-		// Final end event.
-		w.Write([]byte("event: end\n"))
-		w.Write([]byte("data: {}\n\n"))
+		// Send SSE event named after the pipeline stage.
+		event := resp.Stage
+		if event == "" {
+			event = "analysis"
+		}
+		extendDeadline()
+		w.Write([]byte("event: " + event + "\n"))
+		w.Write([]byte("data: "))
+		w.Write(payload)
+		w.Write([]byte("\n\n"))
 		flusher.Flush()
+
+		// Small delay to regulate stream.
+		time.Sleep(10 * time.Millisecond)
 	}
+
+	// Final end event.
+	extendDeadline()
+	w.Write([]byte("event: end\n"))
+	w.Write([]byte("data: {}\n\n"))
+	flusher.Flush()
+}
+
+// writeQuotaExceeded sends a 429 with Retry-After set to the quota reset time.
+func writeQuotaExceeded(w http.ResponseWriter, logger *slog.Logger, e *quota.ExceededError) {
+	retryAfter := int(time.Until(e.ResetAt).Seconds()) + 1
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeJSON(w, logger, http.StatusTooManyRequests, QuotaExceededResponse{
+		Error:   e.Error(),
+		Limit:   e.Limit,
+		Used:    e.Used,
+		Max:     e.Max,
+		ResetAt: e.ResetAt,
+	})
 }
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/compiai/engine/pkg/jwk"
+)
+
+// maxAuthBodyBytes caps the size of authentication request bodies.
+const maxAuthBodyBytes = 1 << 16
+
+// LoginRequest carries username/password credentials.
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest carries a refresh token to exchange for a new pair.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// WalletChallengeResponse carries the message a wallet must sign to log in.
+type WalletChallengeResponse struct {
+	Wallet    string    `json:"wallet"`
+	Nonce     string    `json:"nonce"`
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// WalletVerifyRequest answers a wallet challenge.
+type WalletVerifyRequest struct {
+	Wallet    string `json:"wallet"`
+	Nonce     string `json:"nonce"`
+	Signature string `json:"signature"` // base58 ed25519 signature of the challenge message
+}
+
+// TokenResponse is returned by the login and refresh endpoints.
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+}
+
+func newTokenResponse(pair user.TokenPair) TokenResponse {
+	return TokenResponse{AccessToken: pair.AccessToken, RefreshToken: pair.RefreshToken, TokenType: "Bearer"}
+}
+
+// registerAuthRoutes mounts the token and key discovery endpoints onto the router.
+func registerAuthRoutes(r chi.Router, authService user.AuthService, keys *jwk.Set, logger *slog.Logger) {
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/login", makeLoginHandler(authService, logger))
+		r.Post("/refresh", makeRefreshHandler(authService, logger))
+		r.Post("/logout", makeLogoutHandler(authService, logger))
+		r.With(Authenticator(authService, logger)).Post("/logout-all", makeLogoutAllHandler(authService, logger))
+		r.Get("/wallet/challenge", makeWalletChallengeHandler(authService, logger))
+		r.Post("/wallet/verify", makeWalletVerifyHandler(authService, logger))
+	})
+	r.Get("/.well-known/jwks.json", makeJWKSHandler(keys, logger))
+}
+
+// makeJWKSHandler publishes the public token verification keys so other
+// services can verify tokens without sharing a secret.
+func makeJWKSHandler(keys *jwk.Set, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeJSON(w, logger, http.StatusOK, keys.JWKS())
+	}
+}
+
+// makeLoginHandler exchanges credentials for an access/refresh token pair.
+func makeLoginHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var reqModel LoginRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxAuthBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if reqModel.Username == "" || reqModel.Password == "" {
+			writeError(w, logger, http.StatusBadRequest, "username and password are required")
+			return
+		}
+
+		pair, err := authService.GenerateTokenPair(req.Context(), user.Credentials{
+			Username: reqModel.Username,
+			Password: reqModel.Password,
+		})
+		switch {
+		case errors.Is(err, user.ErrInvalidCredentials):
+			writeError(w, logger, http.StatusUnauthorized, "invalid username or password")
+			return
+		case err != nil:
+			logger.Error("login failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not log in")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newTokenResponse(pair))
+	}
+}
+
+// makeRefreshHandler exchanges a refresh token for a new token pair.
+func makeRefreshHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var reqModel RefreshRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxAuthBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if reqModel.RefreshToken == "" {
+			writeError(w, logger, http.StatusBadRequest, "refreshToken is required")
+			return
+		}
+
+		pair, err := authService.Refresh(req.Context(), reqModel.RefreshToken)
+		switch {
+		case errors.Is(err, user.ErrInvalidToken):
+			writeError(w, logger, http.StatusUnauthorized, "invalid refresh token")
+			return
+		case err != nil:
+			logger.Error("refresh failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not refresh token")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newTokenResponse(pair))
+	}
+}
+
+// makeLogoutHandler revokes the session the given refresh token belongs to.
+func makeLogoutHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var reqModel RefreshRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxAuthBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if reqModel.RefreshToken == "" {
+			writeError(w, logger, http.StatusBadRequest, "refreshToken is required")
+			return
+		}
+
+		err := authService.Logout(req.Context(), reqModel.RefreshToken)
+		switch {
+		case errors.Is(err, user.ErrInvalidToken):
+			writeError(w, logger, http.StatusUnauthorized, "invalid refresh token")
+			return
+		case err != nil:
+			logger.Error("logout failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not log out")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// makeLogoutAllHandler revokes every session of the authenticated user.
+func makeLogoutAllHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		userID, ok := UserIDFromContext(req.Context())
+		if !ok {
+			writeError(w, logger, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+		if err := authService.LogoutAll(req.Context(), userID); err != nil {
+			writeError(w, logger, http.StatusInternalServerError, "could not log out")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// makeWalletChallengeHandler issues a sign-in message for the wallet given
+// in the "wallet" query parameter.
+func makeWalletChallengeHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		challenge, err := authService.WalletChallenge(req.Context(), req.URL.Query().Get("wallet"))
+		switch {
+		case errors.Is(err, user.ErrInvalidWallet):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			logger.Error("wallet challenge failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not create challenge")
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, logger, http.StatusOK, WalletChallengeResponse{
+			Wallet:    challenge.Wallet,
+			Nonce:     challenge.Nonce,
+			Message:   challenge.Message,
+			ExpiresAt: challenge.ExpiresAt,
+		})
+	}
+}
+
+// makeWalletVerifyHandler exchanges a signed challenge for a token pair.
+func makeWalletVerifyHandler(authService user.AuthService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var reqModel WalletVerifyRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxAuthBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		pair, err := authService.VerifyWallet(req.Context(), user.WalletSignature{
+			Wallet:    reqModel.Wallet,
+			Nonce:     reqModel.Nonce,
+			Signature: reqModel.Signature,
+		})
+		switch {
+		case errors.Is(err, user.ErrInvalidWallet):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, user.ErrInvalidChallenge), errors.Is(err, user.ErrInvalidSignature):
+			writeError(w, logger, http.StatusUnauthorized, err.Error())
+			return
+		case err != nil:
+			logger.Error("wallet login failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not log in")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newTokenResponse(pair))
+	}
+}
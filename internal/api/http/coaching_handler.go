@@ -0,0 +1,239 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"log/slog"
+
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/coaching"
+	"github.com/compiai/engine/internal/core/domain/user"
+)
+
+// InviteRequest asks a player for access to their data.
+type InviteRequest struct {
+	PlayerID uuid.UUID      `json:"playerId"`
+	Scopes   []access.Scope `json:"scopes"`
+}
+
+// InvitationResponse describes a coach invitation.
+type InvitationResponse struct {
+	ID        uuid.UUID                 `json:"id"`
+	CoachID   uuid.UUID                 `json:"coachId"`
+	PlayerID  uuid.UUID                 `json:"playerId"`
+	Scopes    []access.Scope            `json:"scopes"`
+	Status    coaching.InvitationStatus `json:"status"`
+	CreatedAt time.Time                 `json:"createdAt"`
+}
+
+func newInvitationResponse(inv coaching.Invitation) InvitationResponse {
+	return InvitationResponse{
+		ID:        inv.ID,
+		CoachID:   inv.CoachID,
+		PlayerID:  inv.PlayerID,
+		Scopes:    inv.Scopes,
+		Status:    inv.Status,
+		CreatedAt: inv.CreatedAt,
+	}
+}
+
+// GrantResponse describes the access a player gave a coach.
+type GrantResponse struct {
+	CoachID   uuid.UUID      `json:"coachId"`
+	PlayerID  uuid.UUID      `json:"playerId"`
+	Scopes    []access.Scope `json:"scopes"`
+	GrantedAt time.Time      `json:"grantedAt"`
+}
+
+func newGrantResponse(g coaching.Grant) GrantResponse {
+	return GrantResponse{CoachID: g.CoachID, PlayerID: g.PlayerID, Scopes: g.Scopes, GrantedAt: g.GrantedAt}
+}
+
+// CoachPlayerResponse is a player in a coach's roster.
+type CoachPlayerResponse struct {
+	ID        uuid.UUID      `json:"id"`
+	Username  string         `json:"username"`
+	Games     []string       `json:"games"`
+	Scopes    []access.Scope `json:"scopes"`
+	GrantedAt time.Time      `json:"grantedAt"`
+}
+
+// registerCoachingRoutes mounts the coach-player linking endpoints onto an
+// authenticated router. Team admins use the coach endpoints too, to collect
+// the view_stats grants their rosters need.
+func registerCoachingRoutes(r chi.Router, coachingService coaching.Service, logger *slog.Logger) {
+	r.Route("/coach", func(r chi.Router) {
+		r.Use(RequireRole(logger, user.RoleCoach, user.RoleTeamAdmin))
+		r.Post("/invitations", makeInviteHandler(coachingService, logger))
+		r.Get("/players", makeCoachPlayersHandler(coachingService, logger))
+		r.Delete("/players/{playerId}", makeRevokeGrantHandler(coachingService, logger, "playerId"))
+	})
+	r.Route("/invitations", func(r chi.Router) {
+		r.Get("/", makePendingInvitationsHandler(coachingService, logger))
+		r.Post("/{id}/accept", makeAnswerInvitationHandler(coachingService, logger, true))
+		r.Post("/{id}/decline", makeAnswerInvitationHandler(coachingService, logger, false))
+	})
+	r.Route("/coaches", func(r chi.Router) {
+		r.Get("/", makePlayerCoachesHandler(coachingService, logger))
+		r.Delete("/{coachId}", makeRevokeGrantHandler(coachingService, logger, "coachId"))
+	})
+}
+
+// makeInviteHandler lets a coach invite a player.
+func makeInviteHandler(coachingService coaching.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		coachID, _ := UserIDFromContext(req.Context())
+		var reqModel InviteRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		inv, err := coachingService.Invite(req.Context(), coachID, reqModel.PlayerID, reqModel.Scopes)
+		switch {
+		case errors.Is(err, coaching.ErrInvalidInvitation):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case errors.Is(err, coaching.ErrAlreadyLinked):
+			writeError(w, logger, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			logger.Error("invite failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not create invitation")
+			return
+		}
+		writeJSON(w, logger, http.StatusCreated, newInvitationResponse(inv))
+	}
+}
+
+// makeCoachPlayersHandler lists the players who granted the coach access.
+func makeCoachPlayersHandler(coachingService coaching.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		coachID, _ := UserIDFromContext(req.Context())
+		links, err := coachingService.Players(req.Context(), coachID)
+		if err != nil {
+			logger.Error("list coach players failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not list players")
+			return
+		}
+		res := make([]CoachPlayerResponse, 0, len(links))
+		for _, l := range links {
+			res = append(res, CoachPlayerResponse{
+				ID:        l.Player.ID,
+				Username:  l.Player.Username,
+				Games:     l.Player.Games,
+				Scopes:    l.Grant.Scopes,
+				GrantedAt: l.Grant.GrantedAt,
+			})
+		}
+		writeJSON(w, logger, http.StatusOK, res)
+	}
+}
+
+// makePendingInvitationsHandler lists invitations awaiting the caller's answer.
+func makePendingInvitationsHandler(coachingService coaching.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		playerID, _ := UserIDFromContext(req.Context())
+		invitations, err := coachingService.PendingInvitations(req.Context(), playerID)
+		if err != nil {
+			logger.Error("list invitations failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not list invitations")
+			return
+		}
+		res := make([]InvitationResponse, 0, len(invitations))
+		for _, inv := range invitations {
+			res = append(res, newInvitationResponse(inv))
+		}
+		writeJSON(w, logger, http.StatusOK, res)
+	}
+}
+
+// makeAnswerInvitationHandler accepts or declines an invitation addressed
+// to the caller.
+func makeAnswerInvitationHandler(coachingService coaching.Service, logger *slog.Logger, accept bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		playerID, _ := UserIDFromContext(req.Context())
+		invitationID, err := uuid.Parse(chi.URLParam(req, "id"))
+		if err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid invitation id")
+			return
+		}
+
+		var grant coaching.Grant
+		if accept {
+			grant, err = coachingService.Accept(req.Context(), playerID, invitationID)
+		} else {
+			err = coachingService.Decline(req.Context(), playerID, invitationID)
+		}
+		switch {
+		case errors.Is(err, coaching.ErrInvitationNotFound):
+			writeError(w, logger, http.StatusNotFound, "no pending invitation with this id")
+			return
+		case err != nil:
+			logger.Error("answer invitation failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not answer invitation")
+			return
+		}
+		if !accept {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newGrantResponse(grant))
+	}
+}
+
+// makePlayerCoachesHandler lists the grants the caller gave to coaches.
+func makePlayerCoachesHandler(coachingService coaching.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		playerID, _ := UserIDFromContext(req.Context())
+		grants, err := coachingService.Coaches(req.Context(), playerID)
+		if err != nil {
+			logger.Error("list coaches failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not list coaches")
+			return
+		}
+		res := make([]GrantResponse, 0, len(grants))
+		for _, g := range grants {
+			res = append(res, newGrantResponse(g))
+		}
+		writeJSON(w, logger, http.StatusOK, res)
+	}
+}
+
+// makeRevokeGrantHandler ends a coach-player link. param names the path
+// parameter holding the other party: a coach revokes by playerId, a player
+// by coachId.
+func makeRevokeGrantHandler(coachingService coaching.Service, logger *slog.Logger, param string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		callerID, _ := UserIDFromContext(req.Context())
+		otherID, err := uuid.Parse(chi.URLParam(req, param))
+		if err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid "+param)
+			return
+		}
+		coachID, playerID := callerID, otherID
+		if param == "coachId" {
+			coachID, playerID = otherID, callerID
+		}
+
+		err = coachingService.Revoke(req.Context(), coachID, playerID)
+		switch {
+		case errors.Is(err, coaching.ErrGrantNotFound):
+			writeError(w, logger, http.StatusNotFound, "no such coach-player link")
+			return
+		case err != nil:
+			logger.Error("revoke grant failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not revoke access")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -1 +1,50 @@
 package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/compiai/engine/internal/core/domain/user"
+)
+
+// ErrorResponse is the JSON body returned for failed non-streaming requests.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON encodes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, logger *slog.Logger, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("encode response failed", "err", err)
+	}
+}
+
+// writeError sends an ErrorResponse with the given status.
+func writeError(w http.ResponseWriter, logger *slog.Logger, status int, msg string) {
+	writeJSON(w, logger, status, ErrorResponse{Error: msg})
+}
+
+// isUserError reports whether err is one of the typed user storage errors
+// handled by writeUserError.
+func isUserError(err error) bool {
+	return errors.Is(err, user.ErrNotFound) || errors.Is(err, user.ErrConflict) || errors.Is(err, user.ErrInvalidFilter)
+}
+
+// writeUserError translates a typed user storage error to 404, 409 or 400.
+func writeUserError(w http.ResponseWriter, logger *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, user.ErrNotFound):
+		writeError(w, logger, http.StatusNotFound, user.ErrNotFound.Error())
+	case errors.Is(err, user.ErrConflict):
+		writeError(w, logger, http.StatusConflict, err.Error())
+	case errors.Is(err, user.ErrInvalidFilter):
+		writeError(w, logger, http.StatusBadRequest, err.Error())
+	default:
+		logger.Error("unexpected user error", "err", err)
+		writeError(w, logger, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,362 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"log/slog"
+
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/compiai/engine/internal/core/domain/metrics"
+)
+
+const (
+	// maxBulkMatches caps the number of records accepted by a single bulk upload.
+	maxBulkMatches = 1000
+	// maxMatchBodyBytes caps the size of match ingestion request bodies.
+	maxMatchBodyBytes = 8 << 20
+	// maxHistoryMatches caps the matches returned by one history request.
+	maxHistoryMatches = 500
+)
+
+// MatchRequest is a single match record pushed by a tracker client.
+type MatchRequest struct {
+	// UserID defaults to the authenticated user and must match it when set.
+	UserID          uuid.UUID `json:"userId"`
+	ExternalID      string    `json:"externalId"`
+	Game            string    `json:"game"`
+	PlayedAt        time.Time `json:"playedAt"`
+	DurationSeconds int       `json:"durationSeconds"`
+	Role            string    `json:"role"`
+	Character       string    `json:"character"`
+	Map             string    `json:"map"`
+	Kills           int       `json:"kills"`
+	Deaths          int       `json:"deaths"`
+	Assists         int       `json:"assists"`
+	Damage          int       `json:"damage"`
+	Objectives      int       `json:"objectives"`
+	Result          string    `json:"result"`
+	// Details carries title-specific stats validated by the game plugin,
+	// e.g. {"rounds": 24, "kastRounds": 17} for Valorant.
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+func (r MatchRequest) toNewMatch() match.NewMatch {
+	return match.NewMatch{
+		UserID:     r.UserID,
+		ExternalID: r.ExternalID,
+		Game:       r.Game,
+		PlayedAt:   r.PlayedAt,
+		Duration:   time.Duration(r.DurationSeconds) * time.Second,
+		Role:       r.Role,
+		Character:  r.Character,
+		Map:        r.Map,
+		Kills:      r.Kills,
+		Deaths:     r.Deaths,
+		Assists:    r.Assists,
+		Damage:     r.Damage,
+		Objectives: r.Objectives,
+		Result:     match.Result(r.Result),
+		Details:    r.Details,
+	}
+}
+
+// MatchResponse identifies a stored match.
+type MatchResponse struct {
+	ID         uuid.UUID `json:"id"`
+	ExternalID string    `json:"externalId"`
+}
+
+// MatchRecordResponse is a stored match in the shape it was uploaded in.
+type MatchRecordResponse struct {
+	ID              uuid.UUID       `json:"id"`
+	ExternalID      string          `json:"externalId"`
+	Game            string          `json:"game"`
+	PlayedAt        time.Time       `json:"playedAt"`
+	DurationSeconds int             `json:"durationSeconds"`
+	Role            string          `json:"role"`
+	Character       string          `json:"character"`
+	Map             string          `json:"map"`
+	Kills           int             `json:"kills"`
+	Deaths          int             `json:"deaths"`
+	Assists         int             `json:"assists"`
+	Damage          int             `json:"damage"`
+	Objectives      int             `json:"objectives"`
+	Result          string          `json:"result"`
+	Details         json.RawMessage `json:"details,omitempty"`
+}
+
+func newMatchRecordResponse(m match.Match) MatchRecordResponse {
+	return MatchRecordResponse{
+		ID:              m.ID,
+		ExternalID:      m.ExternalID,
+		Game:            m.Game,
+		PlayedAt:        m.PlayedAt,
+		DurationSeconds: int(m.Duration.Seconds()),
+		Role:            m.Role,
+		Character:       m.Character,
+		Map:             m.Map,
+		Kills:           m.Kills,
+		Deaths:          m.Deaths,
+		Assists:         m.Assists,
+		Damage:          m.Damage,
+		Objectives:      m.Objectives,
+		Result:          string(m.Result),
+		Details:         m.Details,
+	}
+}
+
+// BulkMatchResponse summarizes a bulk upload.
+type BulkMatchResponse struct {
+	Inserted   []MatchResponse     `json:"inserted"`
+	Duplicates []string            `json:"duplicates"`
+	Rejected   []RejectedMatchItem `json:"rejected"`
+}
+
+// RejectedMatchItem reports a bulk entry that failed validation.
+type RejectedMatchItem struct {
+	Index      int    `json:"index"`
+	ExternalID string `json:"externalId,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// registerMatchRoutes mounts match ingestion endpoints and the per-user
+// match history and stats reads onto the router. Reads of another user's
+// data need the view_history or view_stats scope.
+func registerMatchRoutes(r chi.Router, matchService match.Service, policy access.Policy, logger *slog.Logger) {
+	r.Post("/matches", makeCreateMatchHandler(matchService, logger))
+	r.Post("/matches:bulk", makeBulkMatchHandler(matchService, logger))
+	r.Get("/users/{id}/matches", makeMatchHistoryHandler(matchService, policy, logger))
+	r.Get("/users/{id}/stats", makeStatsHandler(matchService, policy, logger))
+}
+
+// makeMatchHistoryHandler lists a user's matches, most recent first. Query
+// parameters: game (repeatable) and limit (default and maximum
+// maxHistoryMatches).
+func makeMatchHistoryHandler(matchService match.Service, policy access.Policy, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		userID, ok := authorizeSubject(w, req, policy, access.ScopeViewHistory, logger)
+		if !ok {
+			return
+		}
+		filter := match.Filter{UserIDs: []uuid.UUID{userID}, Games: req.URL.Query()["game"], Limit: maxHistoryMatches}
+		if raw := req.URL.Query().Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > maxHistoryMatches {
+				writeError(w, logger, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxHistoryMatches))
+				return
+			}
+			filter.Limit = limit
+		}
+
+		matches, err := matchService.Find(req.Context(), filter)
+		if err != nil {
+			logger.Error("match history failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not load matches")
+			return
+		}
+		res := make([]MatchRecordResponse, 0, len(matches))
+		for _, m := range matches {
+			res = append(res, newMatchRecordResponse(m))
+		}
+		writeJSON(w, logger, http.StatusOK, res)
+	}
+}
+
+// makeStatsHandler computes a user's advanced metrics over their whole
+// history, optionally restricted to the games given as game parameters.
+func makeStatsHandler(matchService match.Service, policy access.Policy, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		userID, ok := authorizeSubject(w, req, policy, access.ScopeViewStats, logger)
+		if !ok {
+			return
+		}
+		matches, err := matchService.Find(req.Context(), match.Filter{UserIDs: []uuid.UUID{userID}, Games: req.URL.Query()["game"]})
+		if err != nil {
+			logger.Error("stats lookup failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not load stats")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, metrics.Compute(matches))
+	}
+}
+
+// authorizeSubject parses the user ID in the path and checks that the
+// caller may exercise scope over that user's data, writing the error
+// response if not.
+func authorizeSubject(w http.ResponseWriter, req *http.Request, policy access.Policy, scope access.Scope, logger *slog.Logger) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		writeError(w, logger, http.StatusBadRequest, "invalid user id")
+		return uuid.Nil, false
+	}
+	principal, _ := PrincipalFromContext(req.Context())
+	err = policy.Authorize(req.Context(), principal, userID, scope)
+	switch {
+	case errors.Is(err, access.ErrForbidden):
+		writeError(w, logger, http.StatusForbidden, fmt.Sprintf("the %s scope is required for this user", scope))
+		return uuid.Nil, false
+	case err != nil:
+		logger.Error("authorization failed", "err", err)
+		writeError(w, logger, http.StatusInternalServerError, "authorization error")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// makeCreateMatchHandler stores a single match record.
+func makeCreateMatchHandler(matchService match.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var reqModel MatchRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxMatchBodyBytes)).Decode(&reqModel); err != nil {
+			logger.Error("invalid request body", "err", err)
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		owned := []MatchRequest{reqModel}
+		if !bindMatchOwner(w, req, logger, owned) {
+			return
+		}
+		reqModel = owned[0]
+
+		m, err := matchService.Create(req.Context(), reqModel.toNewMatch())
+		switch {
+		case errors.Is(err, match.ErrInvalidMatch):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, match.ErrDuplicate):
+			writeError(w, logger, http.StatusConflict, fmt.Sprintf("match %q already exists", reqModel.ExternalID))
+			return
+		case err != nil:
+			logger.Error("create match failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not store match")
+			return
+		}
+		writeJSON(w, logger, http.StatusCreated, MatchResponse{ID: m.ID, ExternalID: m.ExternalID})
+	}
+}
+
+// makeBulkMatchHandler stores a batch of match records sent either as a JSON
+// array or as newline-delimited JSON objects.
+func makeBulkMatchHandler(matchService match.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		reqModels, err := decodeMatchBatch(http.MaxBytesReader(w, req.Body, maxMatchBodyBytes))
+		if err != nil {
+			logger.Error("invalid request body", "err", err)
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(reqModels) == 0 {
+			writeError(w, logger, http.StatusBadRequest, "no matches in request body")
+			return
+		}
+		if !bindMatchOwner(w, req, logger, reqModels) {
+			return
+		}
+
+		newMatches := make([]match.NewMatch, 0, len(reqModels))
+		for _, rm := range reqModels {
+			newMatches = append(newMatches, rm.toNewMatch())
+		}
+		result, err := matchService.Ingest(req.Context(), newMatches)
+		if err != nil {
+			logger.Error("bulk ingest failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not store matches")
+			return
+		}
+
+		res := BulkMatchResponse{
+			Inserted:   make([]MatchResponse, 0, len(result.Inserted)),
+			Duplicates: make([]string, 0, len(result.Duplicates)),
+			Rejected:   make([]RejectedMatchItem, 0, len(result.Rejected)),
+		}
+		for _, m := range result.Inserted {
+			res.Inserted = append(res.Inserted, MatchResponse{ID: m.ID, ExternalID: m.ExternalID})
+		}
+		res.Duplicates = append(res.Duplicates, result.Duplicates...)
+		for _, rj := range result.Rejected {
+			res.Rejected = append(res.Rejected, RejectedMatchItem{Index: rj.Index, ExternalID: rj.ExternalID, Reason: rj.Reason})
+		}
+		writeJSON(w, logger, http.StatusOK, res)
+	}
+}
+
+// bindMatchOwner assigns the authenticated user to every record, rejecting
+// the request with 403 if any record names a different user.
+func bindMatchOwner(w http.ResponseWriter, req *http.Request, logger *slog.Logger, reqModels []MatchRequest) bool {
+	userID, ok := UserIDFromContext(req.Context())
+	if !ok {
+		writeError(w, logger, http.StatusUnauthorized, "unauthenticated")
+		return false
+	}
+	for i := range reqModels {
+		if reqModels[i].UserID != uuid.Nil && reqModels[i].UserID != userID {
+			writeError(w, logger, http.StatusForbidden, "matches can only be uploaded for the authenticated user")
+			return false
+		}
+		reqModels[i].UserID = userID
+	}
+	return true
+}
+
+// decodeMatchBatch reads either a JSON array of matches or a stream of
+// newline-delimited match objects, depending on the first non-space byte.
+func decodeMatchBatch(body io.Reader) ([]MatchRequest, error) {
+	br := bufio.NewReader(body)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	dec := json.NewDecoder(br)
+	var out []MatchRequest
+	if first == '[' {
+		if err := dec.Decode(&out); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+	} else {
+		for line := 1; ; line++ {
+			var rm MatchRequest
+			if err := dec.Decode(&rm); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("invalid NDJSON record %d: %w", line, err)
+			}
+			out = append(out, rm)
+			if len(out) > maxBulkMatches {
+				break
+			}
+		}
+	}
+	if len(out) > maxBulkMatches {
+		return nil, fmt.Errorf("too many matches: at most %d per request", maxBulkMatches)
+	}
+	return out, nil
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"log/slog"
+
+	"github.com/compiai/engine/internal/core/domain/user"
+)
+
+type contextKey int
+
+const principalKey contextKey = iota
+
+// Authenticator rejects requests without a valid bearer access token and
+// stores the token's principal in the request context.
+func Authenticator(authService user.AuthService, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			token, ok := bearerToken(req)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeError(w, logger, http.StatusUnauthorized, "missing bearer token")
+				return
+			}
+			principal, err := authService.Authenticate(req.Context(), token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, logger, http.StatusUnauthorized, "invalid access token")
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), principalKey, principal)))
+		})
+	}
+}
+
+// RequireRole rejects authenticated requests whose principal holds none of
+// roles. It must run after Authenticator.
+func RequireRole(logger *slog.Logger, roles ...user.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			principal, ok := PrincipalFromContext(req.Context())
+			if !ok {
+				writeError(w, logger, http.StatusUnauthorized, "unauthenticated")
+				return
+			}
+			if !principal.HasRole(roles...) {
+				writeError(w, logger, http.StatusForbidden, "insufficient role")
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// PrincipalFromContext returns the authenticated caller set by Authenticator.
+func PrincipalFromContext(ctx context.Context) (user.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(user.Principal)
+	return principal, ok
+}
+
+// UserIDFromContext returns the authenticated user set by Authenticator.
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	return principal.UserID, ok
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,295 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"log/slog"
+
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/agent/stat_analyzer"
+	"github.com/compiai/engine/internal/core/domain/team"
+	"github.com/compiai/engine/internal/core/domain/user"
+)
+
+// CreateTeamRequest names a new team and the game it competes in.
+type CreateTeamRequest struct {
+	Name string `json:"name"`
+	Game string `json:"game"`
+}
+
+// TeamMemberRequest sets a roster member's in-game role.
+type TeamMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// TeamResponse describes a team and its roster.
+type TeamResponse struct {
+	ID        uuid.UUID            `json:"id"`
+	Name      string               `json:"name"`
+	Game      string               `json:"game"`
+	OwnerID   uuid.UUID            `json:"ownerId"`
+	Roster    []TeamMemberResponse `json:"roster"`
+	CreatedAt time.Time            `json:"createdAt"`
+}
+
+// TeamMemberResponse is a player on a team's roster.
+type TeamMemberResponse struct {
+	UserID   uuid.UUID `json:"userId"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+func newTeamResponse(t team.Team) TeamResponse {
+	roster := make([]TeamMemberResponse, 0, len(t.Roster))
+	for _, m := range t.Roster {
+		roster = append(roster, TeamMemberResponse{UserID: m.UserID, Role: m.Role, JoinedAt: m.JoinedAt})
+	}
+	return TeamResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		Game:      t.Game,
+		OwnerID:   t.OwnerID,
+		Roster:    roster,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// registerTeamRoutes mounts the team management and roster analysis
+// endpoints onto an authenticated router.
+func registerTeamRoutes(r chi.Router, teamService team.Service, policy access.Policy, agent stat_analyzer.Agent, logger *slog.Logger) {
+	r.Route("/teams", func(r chi.Router) {
+		r.With(RequireRole(logger, user.RoleTeamAdmin, user.RoleCoach, user.RolePlatformAdmin)).
+			Post("/", makeCreateTeamHandler(teamService, logger))
+		r.Get("/", makeListTeamsHandler(teamService, logger))
+		r.Get("/{id}", makeGetTeamHandler(teamService, logger))
+		r.Put("/{id}/members/{userId}", makePutTeamMemberHandler(teamService, logger))
+		r.Delete("/{id}/members/{userId}", makeDeleteTeamMemberHandler(teamService, logger))
+		r.Post("/{id}/analysis", makeTeamAnalysisHandler(teamService, policy, agent, logger))
+	})
+}
+
+// makeCreateTeamHandler creates a team owned by the caller.
+func makeCreateTeamHandler(teamService team.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ownerID, _ := UserIDFromContext(req.Context())
+		var reqModel CreateTeamRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		t, err := teamService.Create(req.Context(), team.NewTeam{Name: reqModel.Name, Game: reqModel.Game, OwnerID: ownerID})
+		switch {
+		case errors.Is(err, team.ErrInvalidTeam):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			logger.Error("create team failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not create team")
+			return
+		}
+		w.Header().Set("Location", "/teams/"+t.ID.String())
+		writeJSON(w, logger, http.StatusCreated, newTeamResponse(t))
+	}
+}
+
+// makeListTeamsHandler lists the teams the caller owns or plays on.
+func makeListTeamsHandler(teamService team.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		callerID, _ := UserIDFromContext(req.Context())
+		ids := []uuid.UUID{callerID}
+		teams, err := teamService.Find(req.Context(), team.Filter{OwnerIDs: ids, MemberIDs: ids})
+		if err != nil {
+			logger.Error("list teams failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not list teams")
+			return
+		}
+		res := make([]TeamResponse, 0, len(teams))
+		for _, t := range teams {
+			res = append(res, newTeamResponse(t))
+		}
+		writeJSON(w, logger, http.StatusOK, res)
+	}
+}
+
+// makeGetTeamHandler returns a team to its owner, its members and platform admins.
+func makeGetTeamHandler(teamService team.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		t, ok := loadTeam(w, req, teamService, logger)
+		if !ok {
+			return
+		}
+		if !canViewTeam(req, t) {
+			writeError(w, logger, http.StatusForbidden, "not a member of this team")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newTeamResponse(t))
+	}
+}
+
+// makePutTeamMemberHandler adds a player to the roster or changes their role.
+func makePutTeamMemberHandler(teamService team.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		t, ok := loadTeam(w, req, teamService, logger)
+		if !ok {
+			return
+		}
+		if !canManageTeam(req, t) {
+			writeError(w, logger, http.StatusForbidden, "only the team owner can manage the roster")
+			return
+		}
+		userID, err := uuid.Parse(chi.URLParam(req, "userId"))
+		if err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		var reqModel TeamMemberRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		t, err = teamService.AddMember(req.Context(), t.ID, userID, reqModel.Role)
+		switch {
+		case errors.Is(err, team.ErrInvalidTeam):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case errors.Is(err, team.ErrTeamNotFound):
+			writeError(w, logger, http.StatusNotFound, "team not found")
+			return
+		case errors.Is(err, team.ErrRosterFull):
+			writeError(w, logger, http.StatusConflict, err.Error())
+			return
+		case errors.Is(err, team.ErrNoConsent):
+			writeError(w, logger, http.StatusForbidden, err.Error())
+			return
+		case err != nil:
+			logger.Error("add team member failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not update roster")
+			return
+		}
+		writeJSON(w, logger, http.StatusOK, newTeamResponse(t))
+	}
+}
+
+// makeDeleteTeamMemberHandler removes a player from the roster. Players may
+// also leave a team on their own.
+func makeDeleteTeamMemberHandler(teamService team.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		t, ok := loadTeam(w, req, teamService, logger)
+		if !ok {
+			return
+		}
+		userID, err := uuid.Parse(chi.URLParam(req, "userId"))
+		if err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		if callerID, _ := UserIDFromContext(req.Context()); callerID != userID && !canManageTeam(req, t) {
+			writeError(w, logger, http.StatusForbidden, "only the team owner can manage the roster")
+			return
+		}
+
+		err = teamService.RemoveMember(req.Context(), t.ID, userID)
+		switch {
+		case errors.Is(err, team.ErrTeamNotFound):
+			writeError(w, logger, http.StatusNotFound, "user is not on the roster")
+			return
+		case err != nil:
+			logger.Error("remove team member failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not update roster")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// makeTeamAnalysisHandler streams a roster-level analysis via Server-Sent
+// Events. The caller's quota pays for it. Consent is checked again here, as
+// players may have revoked it since joining: the caller must be allowed to
+// view the stats of every member.
+func makeTeamAnalysisHandler(teamService team.Service, policy access.Policy, agent stat_analyzer.Agent, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		t, ok := loadTeam(w, req, teamService, logger)
+		if !ok {
+			return
+		}
+		if !canViewTeam(req, t) {
+			writeError(w, logger, http.StatusForbidden, "not a member of this team")
+			return
+		}
+		if len(t.Roster) == 0 {
+			writeError(w, logger, http.StatusUnprocessableEntity, stat_analyzer.ErrEmptyRoster.Error())
+			return
+		}
+		principal, _ := PrincipalFromContext(req.Context())
+		for _, m := range t.Roster {
+			err := policy.Authorize(req.Context(), principal, m.UserID, access.ScopeViewStats)
+			switch {
+			case errors.Is(err, access.ErrForbidden):
+				writeError(w, logger, http.StatusForbidden, "not allowed to view the stats of every roster member")
+				return
+			case err != nil:
+				logger.Error("authorization failed", "err", err)
+				writeError(w, logger, http.StatusInternalServerError, "analysis error")
+				return
+			}
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, logger, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		callerID, _ := UserIDFromContext(req.Context())
+		stream, err := agent.BuildTeamAnalysis(req.Context(), stat_analyzer.BuildTeamAnalysisRequest{TeamID: t.ID, RequestedBy: callerID})
+		if err != nil {
+			logger.Error("team analysis build error", "err", err, "teamId", t.ID)
+			writeAnalysisError(w, logger, err)
+			return
+		}
+		streamAnalysis(w, flusher, logger, stream)
+	}
+}
+
+// loadTeam resolves the {id} path parameter, writing the error response
+// when the team cannot be loaded.
+func loadTeam(w http.ResponseWriter, req *http.Request, teamService team.Service, logger *slog.Logger) (team.Team, bool) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		writeError(w, logger, http.StatusBadRequest, "invalid team id")
+		return team.Team{}, false
+	}
+	t, err := teamService.FindOne(req.Context(), id)
+	switch {
+	case errors.Is(err, team.ErrTeamNotFound):
+		writeError(w, logger, http.StatusNotFound, "team not found")
+		return team.Team{}, false
+	case err != nil:
+		logger.Error("team lookup failed", "err", err)
+		writeError(w, logger, http.StatusInternalServerError, "could not load team")
+		return team.Team{}, false
+	}
+	return t, true
+}
+
+// canManageTeam reports whether the caller owns the team or is a platform admin.
+func canManageTeam(req *http.Request, t team.Team) bool {
+	principal, _ := PrincipalFromContext(req.Context())
+	return principal.UserID == t.OwnerID || principal.HasRole(user.RolePlatformAdmin)
+}
+
+// canViewTeam additionally lets roster members see their team.
+func canViewTeam(req *http.Request, t team.Team) bool {
+	principal, _ := PrincipalFromContext(req.Context())
+	return canManageTeam(req, t) || t.HasMember(principal.UserID)
+}
@@ -0,0 +1,294 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"log/slog"
+
+	"github.com/compiai/engine/internal/core/domain/quota"
+	"github.com/compiai/engine/internal/core/domain/user"
+)
+
+// maxUserBodyBytes caps the size of user request bodies.
+const maxUserBodyBytes = 1 << 16
+
+// RegisterUserRequest is the signup payload.
+type RegisterUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// UserCreatedResponse identifies a newly registered user.
+type UserCreatedResponse struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// UserResponse describes a user without their credentials.
+type UserResponse struct {
+	ID           uuid.UUID   `json:"id"`
+	Username     string      `json:"username"`
+	SolanaWallet string      `json:"solanaWallet,omitempty"`
+	Games        []string    `json:"games"`
+	Roles        []user.Role `json:"roles"`
+	CreatedAt    time.Time   `json:"createdAt"`
+}
+
+func newUserResponse(u user.User) UserResponse {
+	return UserResponse{
+		ID:           u.ID,
+		Username:     u.Username,
+		SolanaWallet: u.SolanaWalletPublicKey,
+		Games:        u.Games,
+		Roles:        u.Roles,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+// UserListResponse is one page of a user listing.
+type UserListResponse struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+	Total      int            `json:"total"`
+}
+
+// SetRolesRequest replaces a user's roles.
+type SetRolesRequest struct {
+	Roles []user.Role `json:"roles"`
+}
+
+// QuotaOverrideRequest replaces a user's quota override. Omitted limits
+// keep the configured default; 0 means unlimited.
+type QuotaOverrideRequest struct {
+	DailyAnalyses *int   `json:"dailyAnalyses"`
+	MonthlyTokens *int   `json:"monthlyTokens"`
+	Reason        string `json:"reason"`
+}
+
+// QuotaLimitsResponse is the effective quota of a user.
+type QuotaLimitsResponse struct {
+	DailyAnalyses int `json:"dailyAnalyses"`
+	MonthlyTokens int `json:"monthlyTokens"`
+}
+
+// registerUserRoutes mounts the public user endpoints onto the router.
+func registerUserRoutes(r chi.Router, userService user.Service, logger *slog.Logger) {
+	r.Post("/users", makeRegisterUserHandler(userService, logger))
+}
+
+// makeRegisterUserHandler creates a user from a username and plaintext password.
+func makeRegisterUserHandler(userService user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var reqModel RegisterUserRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		id, err := userService.Register(req.Context(), user.NewUser{
+			Username: reqModel.Username,
+			Password: reqModel.Password,
+		})
+		switch {
+		case errors.Is(err, user.ErrInvalidUser):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("register user failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not register user")
+			return
+		}
+		w.Header().Set("Location", "/users/"+id.String())
+		writeJSON(w, logger, http.StatusCreated, UserCreatedResponse{ID: id})
+	}
+}
+
+// registerAdminRoutes mounts platform administration endpoints, including
+// the user listing, onto an authenticated router.
+func registerAdminRoutes(r chi.Router, userService user.Service, quotaService quota.Service, logger *slog.Logger) {
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(RequireRole(logger, user.RolePlatformAdmin))
+		r.Put("/users/{id}/roles", makeSetRolesHandler(userService, logger))
+		r.Get("/users/{id}/quota", makeGetQuotaHandler(userService, quotaService, logger))
+		r.Put("/users/{id}/quota", makeSetQuotaHandler(userService, quotaService, logger))
+		r.Delete("/users/{id}/quota", makeRemoveQuotaHandler(userService, quotaService, logger))
+	})
+	r.With(RequireRole(logger, user.RolePlatformAdmin)).Get("/users", makeListUsersHandler(userService, logger))
+}
+
+// makeListUsersHandler pages through users for admin tooling. Query
+// parameters: username (prefix), q (substring), game (repeatable), sort
+// (username or createdAt, "-" prefix for descending), limit and cursor.
+func makeListUsersHandler(userService user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		query := user.ListQuery{
+			Filter: user.Filter{
+				UsernamePrefix:   params.Get("username"),
+				UsernameContains: params.Get("q"),
+				Games:            params["game"],
+			},
+			Cursor: params.Get("cursor"),
+		}
+		sortKey := params.Get("sort")
+		if rest, ok := strings.CutPrefix(sortKey, "-"); ok {
+			sortKey, query.Desc = rest, true
+		}
+		query.Sort = user.SortKey(sortKey)
+		if raw := params.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil {
+				writeError(w, logger, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			query.Limit = limit
+		}
+
+		page, err := userService.List(req.Context(), query)
+		switch {
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("list users failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not list users")
+			return
+		}
+		res := UserListResponse{Users: make([]UserResponse, 0, len(page.Users)), NextCursor: page.NextCursor, Total: page.Total}
+		for _, u := range page.Users {
+			res.Users = append(res.Users, newUserResponse(u))
+		}
+		writeJSON(w, logger, http.StatusOK, res)
+	}
+}
+
+// makeSetRolesHandler replaces the roles of the user in the path.
+func makeSetRolesHandler(userService user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, err := uuid.Parse(chi.URLParam(req, "id"))
+		if err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		var reqModel SetRolesRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		err = userService.SetRoles(req.Context(), id, reqModel.Roles)
+		switch {
+		case errors.Is(err, user.ErrInvalidRole):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case isUserError(err):
+			writeUserError(w, logger, err)
+			return
+		case err != nil:
+			logger.Error("set roles failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not update roles")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// makeGetQuotaHandler reports the effective quota of the user in the path.
+func makeGetQuotaHandler(userService user.Service, quotaService quota.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := loadAdminSubject(w, req, userService, logger)
+		if !ok {
+			return
+		}
+		writeQuotaLimits(w, req, quotaService, id, logger)
+	}
+}
+
+// makeSetQuotaHandler replaces the quota override of the user in the path.
+func makeSetQuotaHandler(userService user.Service, quotaService quota.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := loadAdminSubject(w, req, userService, logger)
+		if !ok {
+			return
+		}
+		var reqModel QuotaOverrideRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxUserBodyBytes)).Decode(&reqModel); err != nil {
+			writeError(w, logger, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		err := quotaService.SetOverride(req.Context(), quota.Override{
+			UserID:        id,
+			DailyAnalyses: reqModel.DailyAnalyses,
+			MonthlyTokens: reqModel.MonthlyTokens,
+			Reason:        reqModel.Reason,
+		})
+		switch {
+		case errors.Is(err, quota.ErrInvalidOverride):
+			writeError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			logger.Error("set quota override failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not set quota")
+			return
+		}
+		writeQuotaLimits(w, req, quotaService, id, logger)
+	}
+}
+
+// makeRemoveQuotaHandler drops the quota override of the user in the path,
+// restoring the configured defaults.
+func makeRemoveQuotaHandler(userService user.Service, quotaService quota.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id, ok := loadAdminSubject(w, req, userService, logger)
+		if !ok {
+			return
+		}
+		if err := quotaService.RemoveOverride(req.Context(), id); err != nil {
+			logger.Error("remove quota override failed", "err", err)
+			writeError(w, logger, http.StatusInternalServerError, "could not remove quota")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// loadAdminSubject resolves the {id} path parameter to an existing user,
+// writing the error response if there is none.
+func loadAdminSubject(w http.ResponseWriter, req *http.Request, userService user.Service, logger *slog.Logger) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		writeError(w, logger, http.StatusBadRequest, "invalid user id")
+		return uuid.Nil, false
+	}
+	_, err = userService.FindOne(req.Context(), user.SingleFilter{ID: &id})
+	switch {
+	case isUserError(err):
+		writeUserError(w, logger, err)
+		return uuid.Nil, false
+	case err != nil:
+		logger.Error("user lookup failed", "err", err)
+		writeError(w, logger, http.StatusInternalServerError, "could not load user")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func writeQuotaLimits(w http.ResponseWriter, req *http.Request, quotaService quota.Service, id uuid.UUID, logger *slog.Logger) {
+	limits, err := quotaService.Limits(req.Context(), id)
+	if err != nil {
+		logger.Error("quota lookup failed", "err", err)
+		writeError(w, logger, http.StatusInternalServerError, "could not load quota")
+		return
+	}
+	writeJSON(w, logger, http.StatusOK, QuotaLimitsResponse{DailyAnalyses: limits.DailyAnalyses, MonthlyTokens: limits.MonthlyTokens})
+}
@@ -0,0 +1,76 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"log/slog"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// Scope is a permission a player can grant a coach over their data.
+type Scope string
+
+const (
+	ScopeViewStats   Scope = "view_stats"
+	ScopeRunAnalysis Scope = "run_analysis"
+	ScopeViewHistory Scope = "view_history"
+)
+
+// Valid reports whether s is a known scope.
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeViewStats, ScopeRunAnalysis, ScopeViewHistory:
+		return true
+	}
+	return false
+}
+
+// GrantChecker reports whether a player granted a coach the given scope.
+type GrantChecker interface {
+	HasGrant(ctx context.Context, coachID, playerID uuid.UUID, scope Scope) (bool, error)
+}
+
+// Policy decides whether a principal may act on another user's data.
+type Policy interface {
+	// Authorize returns ErrForbidden unless principal may exercise scope
+	// over subject's data.
+	Authorize(ctx context.Context, principal user.Principal, subject uuid.UUID, scope Scope) error
+}
+
+type policy struct {
+	logger *slog.Logger
+	grants GrantChecker
+}
+
+// NewPolicy creates a Policy: users always act on their own data, platform
+// admins on anyone's, and coaches and team admins on players who granted
+// them the scope. grants may be nil, in which case coaches and team admins
+// get no access to other users.
+func NewPolicy(logger *slog.Logger, grants GrantChecker) Policy {
+	return &policy{
+		logger: logger.WithGroup("core-access-policy"),
+		grants: grants,
+	}
+}
+
+func (p *policy) Authorize(ctx context.Context, principal user.Principal, subject uuid.UUID, scope Scope) error {
+	switch {
+	case principal.UserID == subject:
+		return nil
+	case principal.HasRole(user.RolePlatformAdmin):
+		return nil
+	case principal.HasRole(user.RoleCoach, user.RoleTeamAdmin) && p.grants != nil:
+		ok, err := p.grants.HasGrant(ctx, principal.UserID, subject, scope)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	p.logger.Warn("access denied", "userId", principal.UserID, "subject", subject, "scope", scope)
+	return ErrForbidden
+}
@@ -0,0 +1,23 @@
+package account
+
+import (
+	"github.com/compiai/engine/internal/core/domain/coaching"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/compiai/engine/internal/core/domain/team"
+	"github.com/compiai/engine/internal/core/domain/usage"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"time"
+)
+
+// Export is everything stored about one user, handed out on request and
+// once more right before the user is erased.
+type Export struct {
+	User        user.User // PasswordHash is cleared
+	Matches     []match.Match
+	Usage       []usage.Record
+	Coaches     []coaching.Grant      // access the user gave coaches as a player
+	Players     []coaching.Grant      // access players gave the user as a coach
+	Invitations []coaching.Invitation // coach invitations awaiting the user's answer
+	Teams       []team.Team           // teams the user owns or plays on
+	ExportedAt  time.Time
+}
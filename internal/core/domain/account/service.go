@@ -0,0 +1,116 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/coaching"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/compiai/engine/internal/core/domain/team"
+	"github.com/compiai/engine/internal/core/domain/usage"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+// Service gathers and erases the data of a user across the domains.
+type Service interface {
+	// Export collects the user's data, or returns user.ErrNotFound.
+	Export(ctx context.Context, userID uuid.UUID) (Export, error)
+	// Delete exports the user's data, then erases the user together with
+	// everything referencing them and returns the export. Teams the user
+	// owns are deleted with their rosters.
+	Delete(ctx context.Context, userID uuid.UUID) (Export, error)
+}
+
+type service struct {
+	logger          *slog.Logger
+	userService     user.Service
+	matchService    match.Service
+	usageService    usage.Service
+	coachingService coaching.Service
+	teamService     team.Service
+	now             func() time.Time
+}
+
+func NewService(
+	logger *slog.Logger,
+	userService user.Service,
+	matchService match.Service,
+	usageService usage.Service,
+	coachingService coaching.Service,
+	teamService team.Service,
+) Service {
+	return &service{
+		logger:          logger.WithGroup("core-account-service"),
+		userService:     userService,
+		matchService:    matchService,
+		usageService:    usageService,
+		coachingService: coachingService,
+		teamService:     teamService,
+		now:             time.Now,
+	}
+}
+
+func (s *service) Export(ctx context.Context, userID uuid.UUID) (Export, error) {
+	usr, err := s.userService.FindOne(ctx, user.SingleFilter{ID: &userID})
+	if err != nil {
+		return Export{}, err
+	}
+	usr.PasswordHash = ""
+	ids := []uuid.UUID{userID}
+
+	matches, err := s.matchService.Find(ctx, match.Filter{UserIDs: ids})
+	if err != nil {
+		return Export{}, fmt.Errorf("export matches: %w", err)
+	}
+	records, err := s.usageService.Find(ctx, usage.Filter{UserIDs: ids})
+	if err != nil {
+		return Export{}, fmt.Errorf("export usage: %w", err)
+	}
+	coaches, err := s.coachingService.Coaches(ctx, userID)
+	if err != nil {
+		return Export{}, fmt.Errorf("export coaches: %w", err)
+	}
+	links, err := s.coachingService.Players(ctx, userID)
+	if err != nil {
+		return Export{}, fmt.Errorf("export players: %w", err)
+	}
+	// only the grants: the players' profiles belong to them
+	players := make([]coaching.Grant, 0, len(links))
+	for _, l := range links {
+		players = append(players, l.Grant)
+	}
+	invitations, err := s.coachingService.PendingInvitations(ctx, userID)
+	if err != nil {
+		return Export{}, fmt.Errorf("export invitations: %w", err)
+	}
+	teams, err := s.teamService.Find(ctx, team.Filter{OwnerIDs: ids, MemberIDs: ids})
+	if err != nil {
+		return Export{}, fmt.Errorf("export teams: %w", err)
+	}
+
+	return Export{
+		User:        usr,
+		Matches:     matches,
+		Usage:       records,
+		Coaches:     coaches,
+		Players:     players,
+		Invitations: invitations,
+		Teams:       teams,
+		ExportedAt:  s.now().UTC(),
+	}, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID uuid.UUID) (Export, error) {
+	export, err := s.Export(ctx, userID)
+	if err != nil {
+		return Export{}, err
+	}
+	if err := s.userService.Delete(ctx, userID); err != nil {
+		return Export{}, err
+	}
+	s.logger.Info("user erased", "userId", userID,
+		"matches", len(export.Matches), "teams", len(export.Teams))
+	return export, nil
+}
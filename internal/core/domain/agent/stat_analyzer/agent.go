@@ -5,26 +5,61 @@ import (
 	"encoding/json"
 	"fmt"
 	prompts "github.com/compiai/engine/internal/core/domain/agent/stat_analyzer/prompt"
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/compiai/engine/internal/core/domain/metrics"
+	"github.com/compiai/engine/internal/core/domain/quota"
+	"github.com/compiai/engine/internal/core/domain/team"
+	"github.com/compiai/engine/internal/core/domain/usage"
 	"github.com/compiai/engine/internal/core/domain/user"
 	"github.com/compiai/engine/pkg/llm"
 	"github.com/google/uuid"
 	"log/slog"
-	"math"
+	"strings"
 	"time"
 )
 
 type BuildAnalysisRequest struct {
 	UserID uuid.UUID
+	// RequestedBy is charged the quota and token usage of the analysis; it
+	// defaults to UserID.
+	RequestedBy uuid.UUID
 }
 
+// Analysis pipeline stages, emitted in this order.
+const (
+	StageProfile = "profile"
+	StagePlan    = "plan"
+)
+
 type BuildAnalysisStreamResponse struct {
 	ID      string `json:"id"`
+	Stage   string `json:"stage"`
 	Content string `json:"content"`
 	Error   error  `json:"error"`
 }
 
+// promptData is the payload rendered into the analysis prompt.
+type promptData struct {
+	Profile  playerProfile   `json:"profile"`
+	Advanced metrics.Summary `json:"advancedMetrics"`
+	Games    []game.Summary  `json:"games"`
+}
+
+// playerProfile is the subset of user.User that is safe to send to an LLM
+// provider: credentials and wallet keys are deliberately left out.
+type playerProfile struct {
+	Username string   `json:"username"`
+	Games    []string `json:"games"`
+}
+
+func newPlayerProfile(u user.User) playerProfile {
+	return playerProfile{Username: u.Username, Games: u.Games}
+}
+
 type Agent interface {
 	BuildAnalysis(ctx context.Context, request BuildAnalysisRequest) (<-chan BuildAnalysisStreamResponse, error)
+	BuildTeamAnalysis(ctx context.Context, request BuildTeamAnalysisRequest) (<-chan BuildAnalysisStreamResponse, error)
 }
 
 type agent struct {
@@ -32,6 +67,11 @@ type agent struct {
 	llmStreamer  llm.Streamer
 	promptLoader prompts.PromptLoader
 	userService  user.Service
+	matchService match.Service
+	gamePlugins  *game.Registry
+	usageService usage.Service
+	quotaService quota.Service
+	teamService  team.Service
 }
 
 // Agent defines the streaming analysis interface
@@ -40,16 +80,28 @@ func NewAgent(
 	streamer llm.Streamer,
 	loader prompts.PromptLoader,
 	usrSvc user.Service,
+	matchSvc match.Service,
+	plugins *game.Registry,
+	usageSvc usage.Service,
+	quotaSvc quota.Service,
+	teamSvc team.Service,
 ) Agent {
 	return &agent{
 		logger:       logger.WithGroup("stat-analyzer-agent"),
 		llmStreamer:  streamer,
 		promptLoader: loader,
 		userService:  usrSvc,
+		matchService: matchSvc,
+		gamePlugins:  plugins,
+		usageService: usageSvc,
+		quotaService: quotaSvc,
+		teamService:  teamSvc,
 	}
 }
 
-// BuildAnalysis performs a multi-stage stats processing and streams an LLM-based analysis
+// BuildAnalysis performs a multi-stage stats processing and streams an LLM-based
+// analysis: first the detailed profile (StageProfile), then an improvement plan
+// (StagePlan) generated with the profile as conversation history.
 func (a *agent) BuildAnalysis(ctx context.Context, req BuildAnalysisRequest) (<-chan BuildAnalysisStreamResponse, error) {
 	// Stage 1: Load raw user profile
 	usr, err := a.userService.FindOne(ctx, user.SingleFilter{ID: &req.UserID})
@@ -58,102 +110,138 @@ func (a *agent) BuildAnalysis(ctx context.Context, req BuildAnalysisRequest) (<-
 		return nil, fmt.Errorf("user lookup: %w", err)
 	}
 
-	// Stage 2: Derive advanced metrics
-	advanced := deriveMetrics(usr)
+	// Stage 2: Load match history and derive advanced metrics
+	matches, err := a.matchService.Find(ctx, match.Filter{UserIDs: []uuid.UUID{usr.ID}})
+	if err != nil {
+		a.logger.Error("match lookup failed", "err", err)
+		return nil, fmt.Errorf("match lookup: %w", err)
+	}
+	advanced := metrics.Compute(matches)
 
-	// Stage 3: Compose combined data for prompting
-	data := struct {
-		Profile  user.User              `json:"profile"`
-		Advanced map[string]interface{} `json:"advancedMetrics"`
-		Request  BuildAnalysisRequest   `json:"request"`
-	}{usr, advanced, req}
+	// Stage 3: Derive title-specific metrics for the games the user plays
+	games, err := a.gamePlugins.Summarize(a.gamePlugins.ForGames(usr.Games), matches)
+	if err != nil {
+		a.logger.Error("game metrics failed", "err", err)
+		return nil, fmt.Errorf("game metrics: %w", err)
+	}
 
-	a.logger.Info("build started...", "data", data)
+	// Stage 4: Compose combined data for prompting
+	data := promptData{
+		Profile:  newPlayerProfile(usr),
+		Advanced: advanced,
+		Games:    games,
+	}
 
-	// Stage 4: Render prompts
-	sys := a.promptLoader.GetSystemPrompt()
+	a.logger.Info("build started...", "userId", usr.ID, "matches", len(matches), "games", len(games))
 
-	usrPr := a.promptLoader.GetUserPrompt()
+	// Stage 5: Render prompts
+	sys, err := a.promptLoader.GetSystemPrompt()
+	if err != nil {
+		return nil, fmt.Errorf("render system prompt: %w", err)
+	}
+	profilePr, err := a.promptLoader.GetDetailedGamingPrompt(data)
+	if err != nil {
+		return nil, fmt.Errorf("render analysis prompt: %w", err)
+	}
+	planPr, err := a.promptLoader.GetImprovementPlanPrompt(data)
+	if err != nil {
+		return nil, fmt.Errorf("render improvement plan prompt: %w", err)
+	}
 
-	// Stage 5: Initiate LLM streaming
-	genReq := llm.GenerateRequest{Prompt: llm.Prompt{System: sys, User: usrPr}}
-	stream, err := a.llmStreamer.Stream(ctx, genReq)
+	// Stage 6: Charge the requester's quota, then initiate LLM streaming of the profile analysis
+	payer := req.RequestedBy
+	if payer == uuid.Nil {
+		payer = usr.ID
+	}
+	if err := a.quotaService.Reserve(ctx, payer); err != nil {
+		a.logger.Warn("analysis rejected by quota", "userId", payer, "err", err)
+		return nil, fmt.Errorf("quota: %w", err)
+	}
+	profileReq := llm.GenerateRequest{Prompt: llm.Prompt{System: sys, User: profilePr}}
+	profileStream, err := a.llmStreamer.Stream(ctx, profileReq)
 	if err != nil {
 		return nil, fmt.Errorf("LLM stream: %w", err)
 	}
 
-	// Stage 6: Process and enrich stream responses
+	// Stage 7: Stream the profile, then chain its output into the improvement plan
 	out := make(chan BuildAnalysisStreamResponse)
 	go func() {
 		defer close(out)
-		for msg := range stream {
-			// Inject timestamp metadata and segment tags
-			segment := time.Now().Format(time.RFC3339Nano)
-			meta := map[string]string{"segment": segment}
-			if payload, err := json.Marshal(meta); err == nil {
-				msg.Response = string(payload) + "\n" + msg.Response
-			}
-			out <- BuildAnalysisStreamResponse{ID: msg.ID, Content: msg.Response, Error: msg.Error}
+
+		profile, ok := a.forward(ctx, payer, StageProfile, profileStream, out)
+		if !ok {
+			return
 		}
+
+		planReq := llm.GenerateRequest{
+			Prompt:  llm.Prompt{System: sys, User: planPr},
+			History: []llm.Conversation{{Request: profilePr, Response: profile}},
+		}
+		planStream, err := a.llmStreamer.Stream(ctx, planReq)
+		if err != nil {
+			a.logger.Error("improvement plan stream failed", "err", err)
+			a.send(ctx, out, BuildAnalysisStreamResponse{Stage: StagePlan, Error: fmt.Errorf("LLM stream: %w", err)})
+			return
+		}
+		a.forward(ctx, payer, StagePlan, planStream, out)
 	}()
 
 	return out, nil
 }
 
-// deriveMetrics computes advanced analytic metrics from user data
-func deriveMetrics(u user.User) map[string]interface{} {
-	metrics := make(map[string]interface{})
-
-	// Example: Kill/Death Ratio
-	kd := float64(countTotalKills(u.Games)) / math.Max(1, float64(countTotalDeaths(u.Games)))
-	metrics["killDeathRatio"] = fmt.Sprintf("%.2f", kd)
-
-	// Example: Engagement consistency score via variance
-	scores := extractEngagementScores(u.Games)
-	metrics["engagementConsistency"] = variance(scores)
-
-	// Example: Popular role synergy cluster (mocked)
-	metrics["synergyCluster"] = clusterRoleSynergy(u.Games)
-
-	return metrics
-}
-
-// countTotalKills tallies kills (stub implementation)
-func countTotalKills(games []string) int {
-	// placeholder: parse and sum
-	return len(games) * 5
-}
-
-// countTotalDeaths tallies deaths (stub implementation)
-func countTotalDeaths(games []string) int {
-	return len(games) * 3
-}
-
-// extractEngagementScores returns mock engagement values
-func extractEngagementScores(games []string) []float64 {
-	var s []float64
-	for i := range games {
-		s = append(s, float64((i%5)+1))
+// forward relays one stage's LLM stream to out, records its token usage
+// against userID and returns the stage's full text. ok is false when the
+// stage failed or the consumer went away, in which case no further stages
+// should run.
+func (a *agent) forward(
+	ctx context.Context,
+	userID uuid.UUID,
+	stage string,
+	stream <-chan llm.GenerateStreamResponse,
+	out chan<- BuildAnalysisStreamResponse,
+) (text string, ok bool) {
+	var (
+		sb       strings.Builder
+		provider string
+	)
+	ok = true
+	for msg := range stream {
+		if msg.Error != nil {
+			ok = false
+		}
+		if msg.Provider != "" {
+			provider = msg.Provider
+		}
+		if msg.Usage != nil {
+			// usage is bookkeeping, not output; a failed write must not break the stream
+			if err := a.usageService.Record(context.WithoutCancel(ctx), userID, *msg.Usage); err != nil {
+				a.logger.Error("usage record failed", "err", err, "stage", stage)
+			}
+			continue
+		}
+		sb.WriteString(msg.Response)
+
+		// Inject timestamp metadata and segment tags
+		content := msg.Response
+		segment := time.Now().Format(time.RFC3339Nano)
+		meta := map[string]string{"segment": segment, "stage": stage}
+		if payload, err := json.Marshal(meta); err == nil {
+			content = string(payload) + "\n" + content
+		}
+		if !a.send(ctx, out, BuildAnalysisStreamResponse{ID: msg.ID, Stage: stage, Content: content, Error: msg.Error}) {
+			ok = false
+		}
 	}
-	return s
+	a.logger.Info("analysis stage finished", "stage", stage, "provider", provider, "ok", ok)
+	return sb.String(), ok
 }
 
-// variance computes variance of a float64 slice
-func variance(data []float64) float64 {
-	mean := 0.0
-	for _, v := range data {
-		mean += v
-	}
-	mean /= float64(len(data))
-	varSum := 0.0
-	for _, v := range data {
-		varSum += (v - mean) * (v - mean)
+// send delivers resp unless ctx is cancelled first.
+func (a *agent) send(ctx context.Context, out chan<- BuildAnalysisStreamResponse, resp BuildAnalysisStreamResponse) bool {
+	select {
+	case out <- resp:
+		return true
+	case <-ctx.Done():
+		return false
 	}
-	return varSum / float64(len(data))
-}
-
-// clusterRoleSynergy returns a stub cluster name
-func clusterRoleSynergy(games []string) string {
-	// pretend clustering logic
-	return "Alpha-Synergy"
 }
@@ -3,17 +3,13 @@ package prompts
 import (
 	"bytes"
 	"embed"
+	"encoding/json"
 	"text/template"
 )
 
 //go:embed *.tmpl
 var promptFS embed.FS
 
-var (
-	SystemPromptTemplate string
-	UserPromptTemplate   string
-)
-
 // PromptLoader loads and executes prompt templates.
 type PromptLoader struct {
 	templates *template.Template
@@ -21,21 +17,20 @@ type PromptLoader struct {
 
 // NewPromptLoader parses all .tmpl files in the package and returns a loader.
 func NewPromptLoader() (*PromptLoader, error) {
-	tmpl, err := template.New("").ParseFS(promptFS, "*.tmpl")
+	tmpl, err := template.New("").Funcs(template.FuncMap{"toJSON": toJSON}).ParseFS(promptFS, "*.tmpl")
 	if err != nil {
 		return nil, err
 	}
 	return &PromptLoader{templates: tmpl}, nil
 }
 
-// GetSystemPrompt returns the system prompt constant from gaming_prompts.go.
-func (pl *PromptLoader) GetSystemPrompt() string {
-	return SystemPromptTemplate
-}
-
-// GetUserPrompt returns the user prompt constant from gaming_prompts.go.
-func (pl *PromptLoader) GetUserPrompt() string {
-	return UserPromptTemplate
+// GetSystemPrompt executes the SystemPrompt template.
+func (pl *PromptLoader) GetSystemPrompt() (string, error) {
+	var buf bytes.Buffer
+	if err := pl.templates.ExecuteTemplate(&buf, "SystemPrompt", nil); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
 
 // GetDetailedGamingPrompt executes the DetailedGamingPrompt template with the given data.
@@ -55,3 +50,21 @@ func (pl *PromptLoader) GetImprovementPlanPrompt(data interface{}) (string, erro
 	}
 	return buf.String(), nil
 }
+
+// GetTeamAnalysisPrompt executes the TeamAnalysisPrompt template with the given data.
+func (pl *PromptLoader) GetTeamAnalysisPrompt(data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := pl.templates.ExecuteTemplate(&buf, "TeamAnalysisPrompt", data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// toJSON renders v as indented JSON for embedding in prompts.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
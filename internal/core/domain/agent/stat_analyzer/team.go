@@ -0,0 +1,144 @@
+package stat_analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/compiai/engine/internal/core/domain/metrics"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/compiai/engine/pkg/llm"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// StageTeam is the single stage of a team analysis.
+const StageTeam = "team"
+
+var ErrEmptyRoster = errors.New("team roster is empty")
+
+type BuildTeamAnalysisRequest struct {
+	TeamID uuid.UUID
+	// RequestedBy is charged the quota and token usage of the analysis.
+	RequestedBy uuid.UUID
+}
+
+// teamPromptData is the payload rendered into the team analysis prompt; the
+// embedded TeamSummary contributes the members, roleCoverage and synergy keys.
+type teamPromptData struct {
+	Team teamProfile `json:"team"`
+	metrics.TeamSummary
+	Games []memberGameSummary `json:"games"`
+}
+
+type teamProfile struct {
+	Name string `json:"name"`
+	Game string `json:"game"`
+}
+
+type memberGameSummary struct {
+	Name    string       `json:"name"`
+	Summary game.Summary `json:"summary"`
+}
+
+// BuildTeamAnalysis aggregates the roster's match histories into role
+// coverage and synergy metrics and streams a team-level coaching narrative
+// (StageTeam).
+func (a *agent) BuildTeamAnalysis(ctx context.Context, req BuildTeamAnalysisRequest) (<-chan BuildAnalysisStreamResponse, error) {
+	// Stage 1: Load the team and its players
+	t, err := a.teamService.FindOne(ctx, req.TeamID)
+	if err != nil {
+		a.logger.Error("team lookup failed", "err", err)
+		return nil, fmt.Errorf("team lookup: %w", err)
+	}
+	if len(t.Roster) == 0 {
+		return nil, ErrEmptyRoster
+	}
+	players, err := a.userService.Find(ctx, user.Filter{IDs: t.MemberIDs()})
+	if err != nil {
+		a.logger.Error("roster lookup failed", "err", err)
+		return nil, fmt.Errorf("roster lookup: %w", err)
+	}
+	names := make(map[uuid.UUID]string, len(players))
+	for _, p := range players {
+		names[p.ID] = p.Username
+	}
+
+	// Stage 2: Load the roster's matches of the team's game
+	matches, err := a.matchService.Find(ctx, match.Filter{UserIDs: t.MemberIDs()})
+	if err != nil {
+		a.logger.Error("match lookup failed", "err", err)
+		return nil, fmt.Errorf("match lookup: %w", err)
+	}
+	plugin, hasPlugin := a.gamePlugins.Lookup(t.Game)
+	byUser := make(map[uuid.UUID][]match.Match, len(t.Roster))
+	for _, m := range matches {
+		if a.isTeamGame(t.Game, m.Game) {
+			byUser[m.UserID] = append(byUser[m.UserID], m)
+		}
+	}
+
+	// Stage 3: Derive roster metrics, role coverage and synergy
+	roster := make([]metrics.RosterMember, 0, len(t.Roster))
+	for _, m := range t.Roster {
+		roster = append(roster, metrics.RosterMember{UserID: m.UserID, Name: names[m.UserID], Role: m.Role})
+	}
+	var lineup []string
+	if hasPlugin {
+		lineup = plugin.Roles()
+	}
+	data := teamPromptData{
+		Team:        teamProfile{Name: t.Name, Game: t.Game},
+		TeamSummary: metrics.ComputeTeam(roster, byUser, lineup),
+		Games:       []memberGameSummary{},
+	}
+	if hasPlugin {
+		for _, m := range roster {
+			summaries, err := a.gamePlugins.Summarize([]game.Plugin{plugin}, byUser[m.UserID])
+			if err != nil {
+				a.logger.Error("game metrics failed", "err", err)
+				return nil, fmt.Errorf("game metrics: %w", err)
+			}
+			data.Games = append(data.Games, memberGameSummary{Name: m.Name, Summary: summaries[0]})
+		}
+	}
+
+	a.logger.Info("team build started...", "teamId", t.ID, "members", len(roster), "matches", len(matches))
+
+	// Stage 4: Render prompts
+	sys, err := a.promptLoader.GetSystemPrompt()
+	if err != nil {
+		return nil, fmt.Errorf("render system prompt: %w", err)
+	}
+	teamPr, err := a.promptLoader.GetTeamAnalysisPrompt(data)
+	if err != nil {
+		return nil, fmt.Errorf("render team analysis prompt: %w", err)
+	}
+
+	// Stage 5: Charge the requester's quota, then stream the narrative
+	if err := a.quotaService.Reserve(ctx, req.RequestedBy); err != nil {
+		a.logger.Warn("team analysis rejected by quota", "userId", req.RequestedBy, "err", err)
+		return nil, fmt.Errorf("quota: %w", err)
+	}
+	stream, err := a.llmStreamer.Stream(ctx, llm.GenerateRequest{Prompt: llm.Prompt{System: sys, User: teamPr}})
+	if err != nil {
+		return nil, fmt.Errorf("LLM stream: %w", err)
+	}
+
+	out := make(chan BuildAnalysisStreamResponse)
+	go func() {
+		defer close(out)
+		a.forward(ctx, req.RequestedBy, StageTeam, stream, out)
+	}()
+	return out, nil
+}
+
+// isTeamGame reports whether a match title belongs to the team's game,
+// resolving aliases through the game plugins.
+func (a *agent) isTeamGame(teamGame, matchGame string) bool {
+	if p, ok := a.gamePlugins.Lookup(matchGame); ok {
+		return p.Title() == teamGame
+	}
+	return strings.EqualFold(strings.TrimSpace(matchGame), teamGame)
+}
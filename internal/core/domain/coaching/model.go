@@ -0,0 +1,74 @@
+package coaching
+
+import (
+	"errors"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrInvalidInvitation  = errors.New("invalid invitation")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrGrantNotFound      = errors.New("grant not found")
+	ErrAlreadyLinked      = errors.New("coach and player are already linked")
+)
+
+// InvitationStatus tracks a coach's invitation through its lifecycle.
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+)
+
+// Invitation is a coach's request for access to a player's data.
+type Invitation struct {
+	ID          uuid.UUID
+	CoachID     uuid.UUID
+	PlayerID    uuid.UUID
+	Scopes      []access.Scope
+	Status      InvitationStatus
+	CreatedAt   time.Time
+	RespondedAt *time.Time
+}
+
+// Grant is the consent a player gave a coach by accepting an invitation.
+type Grant struct {
+	CoachID   uuid.UUID
+	PlayerID  uuid.UUID
+	Scopes    []access.Scope
+	GrantedAt time.Time
+}
+
+// Allows reports whether the grant includes scope.
+func (g Grant) Allows(scope access.Scope) bool {
+	for _, s := range g.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// PlayerLink is a player a coach has access to.
+type PlayerLink struct {
+	Player user.User
+	Grant  Grant
+}
+
+// validateScopes requires at least one scope and rejects unknown ones.
+func validateScopes(scopes []access.Scope) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidInvitation)
+	}
+	for _, s := range scopes {
+		if !s.Valid() {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidInvitation, s)
+		}
+	}
+	return nil
+}
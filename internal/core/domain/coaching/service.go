@@ -0,0 +1,181 @@
+package coaching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+type InvitationFilter struct {
+	CoachIDs  []uuid.UUID
+	PlayerIDs []uuid.UUID
+	Statuses  []InvitationStatus
+}
+
+type GrantFilter struct {
+	CoachIDs  []uuid.UUID
+	PlayerIDs []uuid.UUID
+}
+
+type Service interface {
+	// Invite asks a player to grant the coach scopes over their data.
+	Invite(ctx context.Context, coachID, playerID uuid.UUID, scopes []access.Scope) (Invitation, error)
+	// PendingInvitations lists the invitations awaiting the player's answer.
+	PendingInvitations(ctx context.Context, playerID uuid.UUID) ([]Invitation, error)
+	// Accept turns the player's pending invitation into a grant.
+	Accept(ctx context.Context, playerID, invitationID uuid.UUID) (Grant, error)
+	Decline(ctx context.Context, playerID, invitationID uuid.UUID) error
+	// Revoke ends the link between a coach and a player; either may call it.
+	Revoke(ctx context.Context, coachID, playerID uuid.UUID) error
+	// Players lists the players who granted the coach access.
+	Players(ctx context.Context, coachID uuid.UUID) ([]PlayerLink, error)
+	// Coaches lists the grants a player gave.
+	Coaches(ctx context.Context, playerID uuid.UUID) ([]Grant, error)
+	access.GrantChecker
+}
+
+type service struct {
+	logger          *slog.Logger
+	coachingStorage Storage
+	userService     user.Service
+	now             func() time.Time
+}
+
+func NewService(logger *slog.Logger, coachingStorage Storage, userService user.Service) Service {
+	return &service{
+		logger:          logger.WithGroup("core-coaching-service"),
+		coachingStorage: coachingStorage,
+		userService:     userService,
+		now:             time.Now,
+	}
+}
+
+func (s *service) Invite(ctx context.Context, coachID, playerID uuid.UUID, scopes []access.Scope) (Invitation, error) {
+	if coachID == playerID {
+		return Invitation{}, fmt.Errorf("%w: coaches cannot invite themselves", ErrInvalidInvitation)
+	}
+	if err := validateScopes(scopes); err != nil {
+		return Invitation{}, err
+	}
+	if _, err := s.userService.FindOne(ctx, user.SingleFilter{ID: &playerID}); err != nil {
+		return Invitation{}, err
+	}
+	if _, err := s.coachingStorage.FindGrant(ctx, coachID, playerID); err == nil {
+		return Invitation{}, ErrAlreadyLinked
+	} else if !errors.Is(err, ErrGrantNotFound) {
+		return Invitation{}, err
+	}
+
+	invitation := Invitation{
+		ID:        uuid.New(),
+		CoachID:   coachID,
+		PlayerID:  playerID,
+		Scopes:    scopes,
+		Status:    InvitationPending,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.coachingStorage.SaveInvitation(ctx, invitation); err != nil {
+		s.logger.Error("save invitation failed", "error", err)
+		return Invitation{}, err
+	}
+	s.logger.Info("coach invited player", "coachId", coachID, "playerId", playerID, "scopes", scopes)
+	return invitation, nil
+}
+
+func (s *service) PendingInvitations(ctx context.Context, playerID uuid.UUID) ([]Invitation, error) {
+	return s.coachingStorage.FindInvitations(ctx, InvitationFilter{
+		PlayerIDs: []uuid.UUID{playerID},
+		Statuses:  []InvitationStatus{InvitationPending},
+	})
+}
+
+func (s *service) Accept(ctx context.Context, playerID, invitationID uuid.UUID) (Grant, error) {
+	if _, err := s.playerInvitation(ctx, playerID, invitationID); err != nil {
+		return Grant{}, err
+	}
+	grant, err := s.coachingStorage.AcceptInvitation(ctx, invitationID, s.now().UTC())
+	if err != nil {
+		return Grant{}, err
+	}
+	s.logger.Info("player granted coach access", "coachId", grant.CoachID, "playerId", playerID, "scopes", grant.Scopes)
+	return grant, nil
+}
+
+func (s *service) Decline(ctx context.Context, playerID, invitationID uuid.UUID) error {
+	if _, err := s.playerInvitation(ctx, playerID, invitationID); err != nil {
+		return err
+	}
+	return s.coachingStorage.DeclineInvitation(ctx, invitationID, s.now().UTC())
+}
+
+func (s *service) Revoke(ctx context.Context, coachID, playerID uuid.UUID) error {
+	if err := s.coachingStorage.DeleteGrant(ctx, coachID, playerID); err != nil {
+		return err
+	}
+	s.logger.Info("coach access revoked", "coachId", coachID, "playerId", playerID)
+	return nil
+}
+
+func (s *service) Players(ctx context.Context, coachID uuid.UUID) ([]PlayerLink, error) {
+	grants, err := s.coachingStorage.FindGrants(ctx, GrantFilter{CoachIDs: []uuid.UUID{coachID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 {
+		return []PlayerLink{}, nil
+	}
+	ids := make([]uuid.UUID, 0, len(grants))
+	for _, g := range grants {
+		ids = append(ids, g.PlayerID)
+	}
+	players, err := s.userService.Find(ctx, user.Filter{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]user.User, len(players))
+	for _, p := range players {
+		byID[p.ID] = p
+	}
+	links := make([]PlayerLink, 0, len(grants))
+	for _, g := range grants {
+		p, ok := byID[g.PlayerID]
+		if !ok {
+			continue // player deleted since granting access
+		}
+		links = append(links, PlayerLink{Player: p, Grant: g})
+	}
+	return links, nil
+}
+
+func (s *service) Coaches(ctx context.Context, playerID uuid.UUID) ([]Grant, error) {
+	return s.coachingStorage.FindGrants(ctx, GrantFilter{PlayerIDs: []uuid.UUID{playerID}})
+}
+
+func (s *service) HasGrant(ctx context.Context, coachID, playerID uuid.UUID, scope access.Scope) (bool, error) {
+	grant, err := s.coachingStorage.FindGrant(ctx, coachID, playerID)
+	if errors.Is(err, ErrGrantNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return grant.Allows(scope), nil
+}
+
+// playerInvitation loads an invitation addressed to playerID. Invitations
+// for other players are reported as not found.
+func (s *service) playerInvitation(ctx context.Context, playerID, invitationID uuid.UUID) (Invitation, error) {
+	invitation, err := s.coachingStorage.FindInvitation(ctx, invitationID)
+	if err != nil {
+		return Invitation{}, err
+	}
+	if invitation.PlayerID != playerID {
+		return Invitation{}, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
@@ -0,0 +1,28 @@
+package coaching
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Storage interface {
+	SaveInvitation(ctx context.Context, invitation Invitation) error
+	// FindInvitation returns ErrInvitationNotFound if there is no such invitation.
+	FindInvitation(ctx context.Context, id uuid.UUID) (Invitation, error)
+	FindInvitations(ctx context.Context, filter InvitationFilter) ([]Invitation, error)
+	// AcceptInvitation marks a pending invitation accepted and stores the
+	// resulting grant in one transaction, replacing any earlier grant
+	// between the two users. It returns ErrInvitationNotFound if the
+	// invitation is no longer pending.
+	AcceptInvitation(ctx context.Context, id uuid.UUID, at time.Time) (Grant, error)
+	// DeclineInvitation marks a pending invitation declined, returning
+	// ErrInvitationNotFound if it is no longer pending.
+	DeclineInvitation(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	// FindGrant returns ErrGrantNotFound if the player granted the coach nothing.
+	FindGrant(ctx context.Context, coachID, playerID uuid.UUID) (Grant, error)
+	FindGrants(ctx context.Context, filter GrantFilter) ([]Grant, error)
+	// DeleteGrant returns ErrGrantNotFound if there was no grant.
+	DeleteGrant(ctx context.Context, coachID, playerID uuid.UUID) error
+}
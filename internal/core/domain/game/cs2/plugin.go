@@ -0,0 +1,149 @@
+package cs2
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/match"
+)
+
+const Title = "cs2"
+
+// Details is the Counter-Strike 2-specific payload of a match.
+type Details struct {
+	Rounds         int `json:"rounds"`
+	KASTRounds     int `json:"kastRounds"`
+	HeadshotKills  int `json:"headshotKills"`
+	UtilityDamage  int `json:"utilityDamage"`
+	EnemiesFlashed int `json:"enemiesFlashed"`
+	OpeningKills   int `json:"openingKills"`
+	OpeningDeaths  int `json:"openingDeaths"`
+}
+
+func (d Details) validate() error {
+	switch {
+	case d.Rounds <= 0:
+		return errors.New("rounds must be positive")
+	case d.KASTRounds < 0 || d.KASTRounds > d.Rounds:
+		return errors.New("kastRounds must be between 0 and rounds")
+	case d.HeadshotKills < 0 || d.UtilityDamage < 0 || d.EnemiesFlashed < 0:
+		return errors.New("counters must not be negative")
+	case d.OpeningKills < 0 || d.OpeningDeaths < 0:
+		return errors.New("opening duel counters must not be negative")
+	}
+	return nil
+}
+
+// Plugin implements game.Plugin for Counter-Strike 2.
+type Plugin struct{}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Title() string {
+	return Title
+}
+
+func (p *Plugin) Aliases() []string {
+	return []string{"cs", "counter-strike 2", "csgo"}
+}
+
+func (p *Plugin) ValidateDetails(raw json.RawMessage) error {
+	var d Details
+	if err := game.DecodeDetails(raw, &d); err != nil {
+		return err
+	}
+	return d.validate()
+}
+
+// DeriveMetrics computes ADR, KAST, headshot kill rate, utility damage and
+// opening duel balance. Matches without details are skipped.
+func (p *Plugin) DeriveMetrics(matches []match.Match) ([]game.Metric, error) {
+	var (
+		damage, rounds, kast, kills, hsKills float64
+		utility, openingKills, openingDeaths float64
+		detailed                             int
+	)
+	for _, m := range matches {
+		if len(m.Details) == 0 {
+			continue
+		}
+		var d Details
+		if err := json.Unmarshal(m.Details, &d); err != nil {
+			return nil, err
+		}
+		detailed++
+		damage += float64(m.Damage)
+		kills += float64(m.Kills)
+		rounds += float64(d.Rounds)
+		kast += float64(d.KASTRounds)
+		hsKills += float64(d.HeadshotKills)
+		utility += float64(d.UtilityDamage)
+		openingKills += float64(d.OpeningKills)
+		openingDeaths += float64(d.OpeningDeaths)
+	}
+	if detailed == 0 {
+		return []game.Metric{}, nil
+	}
+	return []game.Metric{
+		{Name: "adr", Value: game.Ratio(damage, rounds), Unit: "damage/round", Description: "average damage per round"},
+		{Name: "kast", Value: 100 * game.Ratio(kast, rounds), Unit: "%", Description: "rounds with a kill, assist, survival or trade"},
+		{Name: "headshotKillRate", Value: 100 * game.Ratio(hsKills, kills), Unit: "%"},
+		{Name: "utilityDamagePerRound", Value: game.Ratio(utility, rounds), Unit: "damage/round"},
+		{Name: "openingDuelWinRate", Value: 100 * game.Ratio(openingKills, openingKills+openingDeaths), Unit: "%"},
+	}, nil
+}
+
+func (p *Plugin) Roles() []string {
+	return []string{"entry", "awper", "rifler", "support"}
+}
+
+func (p *Plugin) CanonicalRole(role string) (string, bool) {
+	if alias, ok := roleAliases[role]; ok {
+		role = alias
+	}
+	_, ok := benchmarks[role]
+	return role, ok
+}
+
+func (p *Plugin) Benchmarks(role string) []game.Benchmark {
+	if alias, ok := roleAliases[role]; ok {
+		role = alias
+	}
+	return benchmarks[role]
+}
+
+var roleAliases = map[string]string{
+	"entry fragger": "entry",
+	"awp":           "awper",
+	"sniper":        "awper",
+	"lurker":        "rifler",
+	"igl":           "support",
+}
+
+// benchmarks are reference values for Premier 15-20k; "elite" approximates
+// FACEIT level 10 averages.
+var benchmarks = map[string][]game.Benchmark{
+	"entry": {
+		{Metric: "adr", Role: "entry", Average: 82, Elite: 92},
+		{Metric: "kast", Role: "entry", Average: 68, Elite: 74},
+		{Metric: "openingDuelWinRate", Role: "entry", Average: 50, Elite: 56},
+	},
+	"awper": {
+		{Metric: "adr", Role: "awper", Average: 75, Elite: 85},
+		{Metric: "kast", Role: "awper", Average: 70, Elite: 76},
+		{Metric: "openingDuelWinRate", Role: "awper", Average: 55, Elite: 62},
+	},
+	"rifler": {
+		{Metric: "adr", Role: "rifler", Average: 78, Elite: 88},
+		{Metric: "kast", Role: "rifler", Average: 71, Elite: 76},
+		{Metric: "headshotKillRate", Role: "rifler", Average: 48, Elite: 55},
+	},
+	"support": {
+		{Metric: "adr", Role: "support", Average: 70, Elite: 80},
+		{Metric: "kast", Role: "support", Average: 72, Elite: 78},
+		{Metric: "utilityDamagePerRound", Role: "support", Average: 6, Elite: 9},
+	},
+}
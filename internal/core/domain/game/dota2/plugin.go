@@ -0,0 +1,144 @@
+package dota2
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/match"
+)
+
+const Title = "dota2"
+
+// Details is the Dota 2-specific payload of a match.
+type Details struct {
+	GoldPerMin  int `json:"goldPerMin"`
+	XPPerMin    int `json:"xpPerMin"`
+	LastHits    int `json:"lastHits"`
+	Denies      int `json:"denies"`
+	TowerDamage int `json:"towerDamage"`
+	HeroHealing int `json:"heroHealing"`
+}
+
+func (d Details) validate() error {
+	if d.GoldPerMin < 0 || d.XPPerMin < 0 || d.LastHits < 0 || d.Denies < 0 || d.TowerDamage < 0 || d.HeroHealing < 0 {
+		return errors.New("counters must not be negative")
+	}
+	return nil
+}
+
+// Plugin implements game.Plugin for Dota 2.
+type Plugin struct{}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Title() string {
+	return Title
+}
+
+func (p *Plugin) Aliases() []string {
+	return []string{"dota", "dota 2"}
+}
+
+func (p *Plugin) ValidateDetails(raw json.RawMessage) error {
+	var d Details
+	if err := game.DecodeDetails(raw, &d); err != nil {
+		return err
+	}
+	return d.validate()
+}
+
+// DeriveMetrics computes GPM, XPM, last hits per minute and tower damage.
+// GPM and XPM are weighted by match duration. Matches without details are skipped.
+func (p *Plugin) DeriveMetrics(matches []match.Match) ([]game.Metric, error) {
+	var (
+		minutes, gold, xp, lastHits, denies, towerDamage float64
+		detailed                                         int
+	)
+	for _, m := range matches {
+		if len(m.Details) == 0 {
+			continue
+		}
+		var d Details
+		if err := json.Unmarshal(m.Details, &d); err != nil {
+			return nil, err
+		}
+		detailed++
+		mins := m.Duration.Minutes()
+		minutes += mins
+		gold += float64(d.GoldPerMin) * mins
+		xp += float64(d.XPPerMin) * mins
+		lastHits += float64(d.LastHits)
+		denies += float64(d.Denies)
+		towerDamage += float64(d.TowerDamage)
+	}
+	if detailed == 0 {
+		return []game.Metric{}, nil
+	}
+	return []game.Metric{
+		{Name: "gpm", Value: game.Ratio(gold, minutes), Unit: "gold/min"},
+		{Name: "xpm", Value: game.Ratio(xp, minutes), Unit: "xp/min"},
+		{Name: "lastHitsPerMin", Value: game.Ratio(lastHits, minutes), Unit: "lh/min"},
+		{Name: "deniesPerMatch", Value: game.Ratio(denies, float64(detailed))},
+		{Name: "towerDamagePerMatch", Value: game.Ratio(towerDamage, float64(detailed))},
+	}, nil
+}
+
+func (p *Plugin) Roles() []string {
+	return []string{"carry", "mid", "offlane", "soft support", "hard support"}
+}
+
+func (p *Plugin) CanonicalRole(role string) (string, bool) {
+	if alias, ok := roleAliases[role]; ok {
+		role = alias
+	}
+	_, ok := benchmarks[role]
+	return role, ok
+}
+
+func (p *Plugin) Benchmarks(role string) []game.Benchmark {
+	if alias, ok := roleAliases[role]; ok {
+		role = alias
+	}
+	return benchmarks[role]
+}
+
+var roleAliases = map[string]string{
+	"pos1":     "carry",
+	"safelane": "carry",
+	"pos2":     "mid",
+	"pos3":     "offlane",
+	"pos4":     "soft support",
+	"pos5":     "hard support",
+	"support":  "soft support",
+}
+
+// benchmarks are reference values around Ancient/Divine; "elite"
+// approximates Immortal averages.
+var benchmarks = map[string][]game.Benchmark{
+	"carry": {
+		{Metric: "gpm", Role: "carry", Average: 600, Elite: 720},
+		{Metric: "xpm", Role: "carry", Average: 680, Elite: 800},
+		{Metric: "lastHitsPerMin", Role: "carry", Average: 7.0, Elite: 9.0},
+	},
+	"mid": {
+		{Metric: "gpm", Role: "mid", Average: 560, Elite: 680},
+		{Metric: "xpm", Role: "mid", Average: 720, Elite: 850},
+		{Metric: "lastHitsPerMin", Role: "mid", Average: 6.0, Elite: 7.8},
+	},
+	"offlane": {
+		{Metric: "gpm", Role: "offlane", Average: 450, Elite: 540},
+		{Metric: "xpm", Role: "offlane", Average: 600, Elite: 700},
+		{Metric: "lastHitsPerMin", Role: "offlane", Average: 4.0, Elite: 5.2},
+	},
+	"soft support": {
+		{Metric: "gpm", Role: "soft support", Average: 330, Elite: 400},
+		{Metric: "xpm", Role: "soft support", Average: 450, Elite: 540},
+	},
+	"hard support": {
+		{Metric: "gpm", Role: "hard support", Average: 280, Elite: 340},
+		{Metric: "xpm", Role: "hard support", Average: 380, Elite: 460},
+	},
+}
@@ -0,0 +1,151 @@
+package league
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/match"
+)
+
+const Title = "league"
+
+// Details is the League of Legends-specific payload of a match.
+type Details struct {
+	CreepScore  int `json:"creepScore"`
+	VisionScore int `json:"visionScore"`
+	GoldEarned  int `json:"goldEarned"`
+	WardsPlaced int `json:"wardsPlaced"`
+	TeamKills   int `json:"teamKills"`
+	DamageShare int `json:"damageShare"` // percent of team champion damage
+}
+
+func (d Details) validate() error {
+	switch {
+	case d.CreepScore < 0 || d.VisionScore < 0 || d.GoldEarned < 0 || d.WardsPlaced < 0:
+		return errors.New("counters must not be negative")
+	case d.TeamKills < 0:
+		return errors.New("teamKills must not be negative")
+	case d.DamageShare < 0 || d.DamageShare > 100:
+		return errors.New("damageShare must be between 0 and 100")
+	}
+	return nil
+}
+
+// Plugin implements game.Plugin for League of Legends.
+type Plugin struct{}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Title() string {
+	return Title
+}
+
+func (p *Plugin) Aliases() []string {
+	return []string{"lol", "league of legends"}
+}
+
+func (p *Plugin) ValidateDetails(raw json.RawMessage) error {
+	var d Details
+	if err := game.DecodeDetails(raw, &d); err != nil {
+		return err
+	}
+	return d.validate()
+}
+
+// DeriveMetrics computes CS/min, vision score, gold/min and kill participation.
+// Matches without details are skipped.
+func (p *Plugin) DeriveMetrics(matches []match.Match) ([]game.Metric, error) {
+	var (
+		minutes, cs, vision, gold, wards float64
+		takedowns, teamKills             float64
+		detailed                         int
+	)
+	for _, m := range matches {
+		if len(m.Details) == 0 {
+			continue
+		}
+		var d Details
+		if err := json.Unmarshal(m.Details, &d); err != nil {
+			return nil, err
+		}
+		detailed++
+		minutes += m.Duration.Minutes()
+		cs += float64(d.CreepScore)
+		vision += float64(d.VisionScore)
+		gold += float64(d.GoldEarned)
+		wards += float64(d.WardsPlaced)
+		takedowns += float64(m.Kills + m.Assists)
+		teamKills += float64(d.TeamKills)
+	}
+	if detailed == 0 {
+		return []game.Metric{}, nil
+	}
+	return []game.Metric{
+		{Name: "csPerMin", Value: game.Ratio(cs, minutes), Unit: "cs/min", Description: "creep score per minute"},
+		{Name: "visionScore", Value: game.Ratio(vision, float64(detailed)), Description: "average vision score per match"},
+		{Name: "visionScorePerMin", Value: game.Ratio(vision, minutes), Unit: "vision/min"},
+		{Name: "goldPerMin", Value: game.Ratio(gold, minutes), Unit: "gold/min"},
+		{Name: "wardsPerMatch", Value: game.Ratio(wards, float64(detailed))},
+		{Name: "killParticipation", Value: 100 * game.Ratio(takedowns, teamKills), Unit: "%", Description: "share of team kills with a kill or assist"},
+	}, nil
+}
+
+func (p *Plugin) Roles() []string {
+	return []string{"top", "jungle", "mid", "bot", "support"}
+}
+
+func (p *Plugin) CanonicalRole(role string) (string, bool) {
+	if alias, ok := roleAliases[role]; ok {
+		role = alias
+	}
+	_, ok := benchmarks[role]
+	return role, ok
+}
+
+func (p *Plugin) Benchmarks(role string) []game.Benchmark {
+	if alias, ok := roleAliases[role]; ok {
+		role = alias
+	}
+	return benchmarks[role]
+}
+
+var roleAliases = map[string]string{
+	"adc":     "bot",
+	"carry":   "bot",
+	"jungler": "jungle",
+	"middle":  "mid",
+	"supp":    "support",
+}
+
+// benchmarks are reference values for Emerald/Diamond solo queue; "elite"
+// approximates Master+ averages.
+var benchmarks = map[string][]game.Benchmark{
+	"top": {
+		{Metric: "csPerMin", Role: "top", Average: 6.8, Elite: 8.2},
+		{Metric: "visionScorePerMin", Role: "top", Average: 0.6, Elite: 0.9},
+		{Metric: "killParticipation", Role: "top", Average: 45, Elite: 55},
+	},
+	"jungle": {
+		{Metric: "csPerMin", Role: "jungle", Average: 5.5, Elite: 6.8},
+		{Metric: "visionScorePerMin", Role: "jungle", Average: 1.0, Elite: 1.4},
+		{Metric: "killParticipation", Role: "jungle", Average: 62, Elite: 72},
+	},
+	"mid": {
+		{Metric: "csPerMin", Role: "mid", Average: 7.2, Elite: 8.8},
+		{Metric: "visionScorePerMin", Role: "mid", Average: 0.7, Elite: 1.0},
+		{Metric: "killParticipation", Role: "mid", Average: 55, Elite: 65},
+	},
+	"bot": {
+		{Metric: "csPerMin", Role: "bot", Average: 7.5, Elite: 9.2},
+		{Metric: "visionScorePerMin", Role: "bot", Average: 0.6, Elite: 0.9},
+		{Metric: "killParticipation", Role: "bot", Average: 58, Elite: 68},
+	},
+	"support": {
+		{Metric: "csPerMin", Role: "support", Average: 1.2, Elite: 1.5},
+		{Metric: "visionScorePerMin", Role: "support", Average: 2.0, Elite: 2.8},
+		{Metric: "killParticipation", Role: "support", Average: 62, Elite: 72},
+	},
+}
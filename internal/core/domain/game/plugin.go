@@ -0,0 +1,189 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compiai/engine/internal/core/domain/match"
+)
+
+// Plugin supplies title-specific match parsing, metrics and role benchmarks.
+type Plugin interface {
+	// Title is the canonical game title, e.g. "valorant".
+	Title() string
+	// Aliases are alternative spellings users and trackers use for the title.
+	Aliases() []string
+	// ValidateDetails checks the title-specific payload attached to a match.
+	ValidateDetails(details json.RawMessage) error
+	// DeriveMetrics computes title-specific metrics over matches of this title.
+	DeriveMetrics(matches []match.Match) ([]Metric, error)
+	// Benchmarks returns reference values for a role; unknown roles yield nil.
+	Benchmarks(role string) []Benchmark
+	// Roles lists the canonical roles of a full lineup in conventional order.
+	Roles() []string
+	// CanonicalRole resolves a role or one of its aliases to the canonical
+	// role; ok is false for unknown roles.
+	CanonicalRole(role string) (canonical string, ok bool)
+}
+
+// Metric is a single derived, title-specific value.
+type Metric struct {
+	Name        string  `json:"name"`
+	Value       float64 `json:"value"`
+	Unit        string  `json:"unit,omitempty"`
+	Description string  `json:"description,omitempty"`
+}
+
+// Benchmark is a reference value of a metric for a role.
+type Benchmark struct {
+	Metric  string  `json:"metric"`
+	Role    string  `json:"role"`
+	Average float64 `json:"average"`
+	Elite   float64 `json:"elite"`
+}
+
+// Summary is the per-title result handed to prompts and APIs.
+type Summary struct {
+	Title      string      `json:"title"`
+	Role       string      `json:"role,omitempty"` // most played role
+	Matches    int         `json:"matches"`
+	Metrics    []Metric    `json:"metrics"`
+	Benchmarks []Benchmark `json:"benchmarks,omitempty"`
+}
+
+// Registry holds plugins keyed by normalized title and alias.
+type Registry struct {
+	plugins map[string]Plugin
+	ordered []Plugin
+}
+
+// NewRegistry creates a registry with the given plugins registered.
+func NewRegistry(plugins ...Plugin) *Registry {
+	r := &Registry{plugins: make(map[string]Plugin)}
+	for _, p := range plugins {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds a plugin under its title and aliases, replacing earlier
+// registrations of the same names.
+func (r *Registry) Register(p Plugin) {
+	r.plugins[normalizeTitle(p.Title())] = p
+	for _, alias := range p.Aliases() {
+		r.plugins[normalizeTitle(alias)] = p
+	}
+	r.ordered = append(r.ordered, p)
+}
+
+// Lookup returns the plugin registered for the title or one of its aliases.
+func (r *Registry) Lookup(title string) (Plugin, bool) {
+	p, ok := r.plugins[normalizeTitle(title)]
+	return p, ok
+}
+
+// ForGames returns the distinct plugins matching the given titles, in the
+// order the titles are listed. Titles without a plugin are skipped.
+func (r *Registry) ForGames(titles []string) []Plugin {
+	seen := make(map[string]struct{})
+	var out []Plugin
+	for _, t := range titles {
+		p, ok := r.Lookup(t)
+		if !ok {
+			continue
+		}
+		if _, dup := seen[p.Title()]; dup {
+			continue
+		}
+		seen[p.Title()] = struct{}{}
+		out = append(out, p)
+	}
+	return out
+}
+
+// ValidateDetails implements match.DetailsValidator. Matches of titles
+// without a plugin and matches without details are accepted as-is.
+func (r *Registry) ValidateDetails(game string, details json.RawMessage) error {
+	if len(details) == 0 {
+		return nil
+	}
+	p, ok := r.Lookup(game)
+	if !ok {
+		return nil
+	}
+	if err := p.ValidateDetails(details); err != nil {
+		return fmt.Errorf("%s details: %w", p.Title(), err)
+	}
+	return nil
+}
+
+// Summarize derives a Summary for each plugin from the matches of its title.
+func (r *Registry) Summarize(plugins []Plugin, matches []match.Match) ([]Summary, error) {
+	byTitle := make(map[string][]match.Match)
+	for _, m := range matches {
+		if p, ok := r.Lookup(m.Game); ok {
+			byTitle[p.Title()] = append(byTitle[p.Title()], m)
+		}
+	}
+
+	summaries := make([]Summary, 0, len(plugins))
+	for _, p := range plugins {
+		titleMatches := byTitle[p.Title()]
+		metrics, err := p.DeriveMetrics(titleMatches)
+		if err != nil {
+			return nil, fmt.Errorf("%s metrics: %w", p.Title(), err)
+		}
+		role := mostPlayedRole(titleMatches)
+		summaries = append(summaries, Summary{
+			Title:      p.Title(),
+			Role:       role,
+			Matches:    len(titleMatches),
+			Metrics:    metrics,
+			Benchmarks: p.Benchmarks(role),
+		})
+	}
+	return summaries, nil
+}
+
+// DecodeDetails unmarshals a details payload, rejecting unknown fields.
+func DecodeDetails(raw json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Ratio divides num by den, returning 0 when den is zero.
+func Ratio(num, den float64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+func mostPlayedRole(matches []match.Match) string {
+	counts := make(map[string]int)
+	for _, m := range matches {
+		if m.Role != "" {
+			counts[normalizeTitle(m.Role)]++
+		}
+	}
+	roles := make([]string, 0, len(counts))
+	for role := range counts {
+		roles = append(roles, role)
+	}
+	// sort for a deterministic pick on ties
+	sort.Strings(roles)
+	best := ""
+	for _, role := range roles {
+		if best == "" || counts[role] > counts[best] {
+			best = role
+		}
+	}
+	return best
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
@@ -0,0 +1,139 @@
+package valorant
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/match"
+)
+
+const Title = "valorant"
+
+// Details is the Valorant-specific payload of a match.
+type Details struct {
+	Rounds      int `json:"rounds"`
+	KASTRounds  int `json:"kastRounds"` // rounds with a kill, assist, survival or trade
+	Headshots   int `json:"headshots"`
+	Bodyshots   int `json:"bodyshots"`
+	Legshots    int `json:"legshots"`
+	FirstKills  int `json:"firstKills"`
+	FirstDeaths int `json:"firstDeaths"`
+}
+
+func (d Details) validate() error {
+	switch {
+	case d.Rounds <= 0:
+		return errors.New("rounds must be positive")
+	case d.KASTRounds < 0 || d.KASTRounds > d.Rounds:
+		return errors.New("kastRounds must be between 0 and rounds")
+	case d.Headshots < 0 || d.Bodyshots < 0 || d.Legshots < 0:
+		return errors.New("shot counters must not be negative")
+	case d.FirstKills < 0 || d.FirstDeaths < 0:
+		return errors.New("first kill/death counters must not be negative")
+	}
+	return nil
+}
+
+// Plugin implements game.Plugin for Valorant.
+type Plugin struct{}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Title() string {
+	return Title
+}
+
+func (p *Plugin) Aliases() []string {
+	return []string{"val"}
+}
+
+func (p *Plugin) ValidateDetails(raw json.RawMessage) error {
+	var d Details
+	if err := game.DecodeDetails(raw, &d); err != nil {
+		return err
+	}
+	return d.validate()
+}
+
+// DeriveMetrics computes ADR, KAST, headshot rate and opening duel balance.
+// Matches without details are skipped.
+func (p *Plugin) DeriveMetrics(matches []match.Match) ([]game.Metric, error) {
+	var (
+		damage, rounds, kast    float64
+		head, shots             float64
+		firstKills, firstDeaths float64
+		detailed                int
+	)
+	for _, m := range matches {
+		if len(m.Details) == 0 {
+			continue
+		}
+		var d Details
+		if err := json.Unmarshal(m.Details, &d); err != nil {
+			return nil, err
+		}
+		detailed++
+		damage += float64(m.Damage)
+		rounds += float64(d.Rounds)
+		kast += float64(d.KASTRounds)
+		head += float64(d.Headshots)
+		shots += float64(d.Headshots + d.Bodyshots + d.Legshots)
+		firstKills += float64(d.FirstKills)
+		firstDeaths += float64(d.FirstDeaths)
+	}
+	if detailed == 0 {
+		return []game.Metric{}, nil
+	}
+	return []game.Metric{
+		{Name: "adr", Value: game.Ratio(damage, rounds), Unit: "damage/round", Description: "average damage per round"},
+		{Name: "kast", Value: 100 * game.Ratio(kast, rounds), Unit: "%", Description: "rounds with a kill, assist, survival or trade"},
+		{Name: "headshotRate", Value: 100 * game.Ratio(head, shots), Unit: "%", Description: "share of hits landing on the head"},
+		{Name: "firstKillsPerMatch", Value: game.Ratio(firstKills, float64(detailed)), Description: "opening kills per match"},
+		{Name: "firstDuelWinRate", Value: 100 * game.Ratio(firstKills, firstKills+firstDeaths), Unit: "%", Description: "opening duels won"},
+	}, nil
+}
+
+func (p *Plugin) Roles() []string {
+	return []string{"duelist", "initiator", "controller", "sentinel"}
+}
+
+func (p *Plugin) CanonicalRole(role string) (string, bool) {
+	_, ok := benchmarks[role]
+	return role, ok
+}
+
+func (p *Plugin) Benchmarks(role string) []game.Benchmark {
+	return benchmarks[role]
+}
+
+// benchmarks are reference values for ranked play around Diamond/Ascendant;
+// "elite" approximates top-500 Radiant averages.
+var benchmarks = map[string][]game.Benchmark{
+	"duelist": {
+		{Metric: "adr", Role: "duelist", Average: 150, Elite: 175},
+		{Metric: "kast", Role: "duelist", Average: 68, Elite: 75},
+		{Metric: "headshotRate", Role: "duelist", Average: 22, Elite: 30},
+		{Metric: "firstDuelWinRate", Role: "duelist", Average: 50, Elite: 58},
+	},
+	"initiator": {
+		{Metric: "adr", Role: "initiator", Average: 135, Elite: 155},
+		{Metric: "kast", Role: "initiator", Average: 72, Elite: 78},
+		{Metric: "headshotRate", Role: "initiator", Average: 20, Elite: 27},
+		{Metric: "firstDuelWinRate", Role: "initiator", Average: 48, Elite: 55},
+	},
+	"controller": {
+		{Metric: "adr", Role: "controller", Average: 125, Elite: 145},
+		{Metric: "kast", Role: "controller", Average: 72, Elite: 79},
+		{Metric: "headshotRate", Role: "controller", Average: 19, Elite: 26},
+		{Metric: "firstDuelWinRate", Role: "controller", Average: 45, Elite: 52},
+	},
+	"sentinel": {
+		{Metric: "adr", Role: "sentinel", Average: 125, Elite: 145},
+		{Metric: "kast", Role: "sentinel", Average: 71, Elite: 78},
+		{Metric: "headshotRate", Role: "sentinel", Average: 20, Elite: 27},
+		{Metric: "firstDuelWinRate", Role: "sentinel", Average: 46, Elite: 53},
+	},
+}
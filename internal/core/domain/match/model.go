@@ -0,0 +1,118 @@
+package match
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidMatch = errors.New("invalid match")
+	ErrDuplicate    = errors.New("duplicate match")
+)
+
+const (
+	maxExternalIDLength = 128
+	maxClockSkew        = 5 * time.Minute
+)
+
+// Result is the outcome of a match from the player's point of view.
+type Result string
+
+const (
+	ResultWin  Result = "win"
+	ResultLoss Result = "loss"
+	ResultDraw Result = "draw"
+)
+
+// Valid reports whether r is one of the known results.
+func (r Result) Valid() bool {
+	switch r {
+	case ResultWin, ResultLoss, ResultDraw:
+		return true
+	}
+	return false
+}
+
+// Match is a single played match of a user.
+type Match struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	ExternalID string // match identifier assigned by the game or tracker
+	Game       string // game title, e.g. "valorant"
+	PlayedAt   time.Time
+	Duration   time.Duration
+	Role       string // role or position played
+	Character  string // champion, agent or hero
+	Map        string
+	Kills      int
+	Deaths     int
+	Assists    int
+	Damage     int
+	Objectives int // objectives taken: plants, towers, dragons, etc.
+	Result     Result
+	Details    json.RawMessage // title-specific payload, parsed by game plugins
+}
+
+type NewMatch struct {
+	UserID     uuid.UUID
+	ExternalID string
+	Game       string
+	PlayedAt   time.Time
+	Duration   time.Duration
+	Role       string
+	Character  string
+	Map        string
+	Kills      int
+	Deaths     int
+	Assists    int
+	Damage     int
+	Objectives int
+	Result     Result
+	Details    json.RawMessage
+}
+
+// Validate checks that a NewMatch carries a complete, plausible record.
+func (m NewMatch) Validate() error {
+	switch {
+	case m.UserID == uuid.Nil:
+		return fmt.Errorf("%w: userId is required", ErrInvalidMatch)
+	case strings.TrimSpace(m.ExternalID) == "":
+		return fmt.Errorf("%w: externalId is required", ErrInvalidMatch)
+	case len(m.ExternalID) > maxExternalIDLength:
+		return fmt.Errorf("%w: externalId exceeds %d characters", ErrInvalidMatch, maxExternalIDLength)
+	case strings.TrimSpace(m.Game) == "":
+		return fmt.Errorf("%w: game is required", ErrInvalidMatch)
+	case m.PlayedAt.IsZero():
+		return fmt.Errorf("%w: playedAt is required", ErrInvalidMatch)
+	case m.PlayedAt.After(time.Now().Add(maxClockSkew)):
+		return fmt.Errorf("%w: playedAt is in the future", ErrInvalidMatch)
+	case m.Duration <= 0:
+		return fmt.Errorf("%w: duration must be positive", ErrInvalidMatch)
+	case m.Kills < 0 || m.Deaths < 0 || m.Assists < 0 || m.Damage < 0 || m.Objectives < 0:
+		return fmt.Errorf("%w: counters must not be negative", ErrInvalidMatch)
+	case !m.Result.Valid():
+		return fmt.Errorf("%w: unknown result %q", ErrInvalidMatch, m.Result)
+	case len(m.Details) > 0 && !json.Valid(m.Details):
+		return fmt.Errorf("%w: details must be valid JSON", ErrInvalidMatch)
+	}
+	return nil
+}
+
+// IngestResult reports the outcome of a batch ingestion.
+type IngestResult struct {
+	Inserted   []Match
+	Duplicates []string // external IDs that were already stored or repeated in the batch
+	Rejected   []Rejection
+}
+
+// Rejection describes a batch entry that failed validation.
+type Rejection struct {
+	Index      int
+	ExternalID string
+	Reason     string
+}
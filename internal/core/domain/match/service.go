@@ -0,0 +1,152 @@
+package match
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+type Filter struct {
+	UserIDs []uuid.UUID
+	Games   []string
+	From    *time.Time // inclusive lower bound on PlayedAt
+	To      *time.Time // exclusive upper bound on PlayedAt
+	Limit   int        // 0 means no limit
+}
+
+type Service interface {
+	Create(ctx context.Context, newMatch NewMatch) (Match, error)
+	Ingest(ctx context.Context, newMatches []NewMatch) (IngestResult, error)
+	FindOne(ctx context.Context, id uuid.UUID) (Match, error)
+	Find(ctx context.Context, filter Filter) ([]Match, error)
+}
+
+// DetailsValidator checks the title-specific Details payload of a match.
+type DetailsValidator interface {
+	ValidateDetails(game string, details json.RawMessage) error
+}
+
+type service struct {
+	logger           *slog.Logger
+	matchStorage     Storage
+	detailsValidator DetailsValidator
+}
+
+func NewService(logger *slog.Logger, matchStorage Storage, detailsValidator DetailsValidator) Service {
+	return &service{
+		logger:           logger.WithGroup("core-match-service"),
+		matchStorage:     matchStorage,
+		detailsValidator: detailsValidator,
+	}
+}
+
+func (s *service) Create(ctx context.Context, newMatch NewMatch) (Match, error) {
+	if err := s.validate(newMatch); err != nil {
+		return Match{}, err
+	}
+	inserted, err := s.matchStorage.Insert(ctx, []Match{fromNewMatch(newMatch)})
+	if err != nil {
+		s.logger.Error("create match failed", "error", err)
+		return Match{}, err
+	}
+	if len(inserted) == 0 {
+		return Match{}, ErrDuplicate
+	}
+	return inserted[0], nil
+}
+
+// Ingest validates and stores a batch of matches. Invalid entries are
+// rejected individually and duplicates are skipped, so one bad record
+// does not fail an entire upload.
+func (s *service) Ingest(ctx context.Context, newMatches []NewMatch) (IngestResult, error) {
+	var (
+		result  IngestResult
+		pending []Match
+	)
+	type key struct {
+		userID     uuid.UUID
+		externalID string
+	}
+	seen := make(map[key]struct{}, len(newMatches))
+	for i, nm := range newMatches {
+		if err := s.validate(nm); err != nil {
+			result.Rejected = append(result.Rejected, Rejection{Index: i, ExternalID: nm.ExternalID, Reason: err.Error()})
+			continue
+		}
+		k := key{nm.UserID, nm.ExternalID}
+		if _, dup := seen[k]; dup {
+			result.Duplicates = append(result.Duplicates, nm.ExternalID)
+			continue
+		}
+		seen[k] = struct{}{}
+		pending = append(pending, fromNewMatch(nm))
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	inserted, err := s.matchStorage.Insert(ctx, pending)
+	if err != nil {
+		s.logger.Error("ingest matches failed", "error", err, "count", len(pending))
+		return IngestResult{}, err
+	}
+	stored := make(map[key]struct{}, len(inserted))
+	for _, m := range inserted {
+		stored[key{m.UserID, m.ExternalID}] = struct{}{}
+	}
+	for _, m := range pending {
+		if _, ok := stored[key{m.UserID, m.ExternalID}]; !ok {
+			result.Duplicates = append(result.Duplicates, m.ExternalID)
+		}
+	}
+	result.Inserted = inserted
+	s.logger.Info("matches ingested",
+		"inserted", len(result.Inserted), "duplicates", len(result.Duplicates), "rejected", len(result.Rejected))
+	return result, nil
+}
+
+func (s *service) FindOne(ctx context.Context, id uuid.UUID) (Match, error) {
+	return s.matchStorage.FindOneByID(ctx, id)
+}
+
+func (s *service) Find(ctx context.Context, filter Filter) ([]Match, error) {
+	return s.matchStorage.Find(ctx, filter)
+}
+
+// validate runs the generic checks followed by the title-specific ones.
+func (s *service) validate(newMatch NewMatch) error {
+	if err := newMatch.Validate(); err != nil {
+		return err
+	}
+	if s.detailsValidator == nil {
+		return nil
+	}
+	if err := s.detailsValidator.ValidateDetails(newMatch.Game, newMatch.Details); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMatch, err)
+	}
+	return nil
+}
+
+func fromNewMatch(newMatch NewMatch) Match {
+	return Match{
+		ID:         uuid.New(),
+		UserID:     newMatch.UserID,
+		ExternalID: newMatch.ExternalID,
+		Game:       newMatch.Game,
+		PlayedAt:   newMatch.PlayedAt.UTC(),
+		Duration:   newMatch.Duration,
+		Role:       newMatch.Role,
+		Character:  newMatch.Character,
+		Map:        newMatch.Map,
+		Kills:      newMatch.Kills,
+		Deaths:     newMatch.Deaths,
+		Assists:    newMatch.Assists,
+		Damage:     newMatch.Damage,
+		Objectives: newMatch.Objectives,
+		Result:     newMatch.Result,
+		Details:    newMatch.Details,
+	}
+}
@@ -0,0 +1,17 @@
+package match
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type Storage interface {
+	Save(ctx context.Context, match Match) error
+	// Insert stores matches in a single transaction, skipping any whose
+	// (UserID, ExternalID) is already stored, and returns the ones inserted.
+	Insert(ctx context.Context, matches []Match) ([]Match, error)
+
+	FindOneByID(ctx context.Context, id uuid.UUID) (Match, error)
+
+	Find(ctx context.Context, filter Filter) ([]Match, error)
+}
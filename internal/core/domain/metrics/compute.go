@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/compiai/engine/internal/core/domain/match"
+)
+
+// RollingWindows are the match counts rolling averages are computed over.
+var RollingWindows = []int{5, 10, 20}
+
+// Compute derives a Summary from matches in any order.
+func Compute(matches []match.Match) Summary {
+	// work on a chronological copy so streaks and rolling windows are stable
+	ordered := make([]match.Match, len(matches))
+	copy(ordered, matches)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].PlayedAt.Before(ordered[j].PlayedAt)
+	})
+
+	s := Summary{
+		Matches: len(ordered),
+		Rolling: []RollingAverage{},
+		ByRole:  breakdown(ordered, func(m match.Match) string { return m.Role }),
+		ByMap:   breakdown(ordered, func(m match.Match) string { return m.Map }),
+		Streaks: streaks(ordered),
+	}
+	for _, m := range ordered {
+		switch m.Result {
+		case match.ResultWin:
+			s.Wins++
+		case match.ResultLoss:
+			s.Losses++
+		case match.ResultDraw:
+			s.Draws++
+		}
+		s.Totals.Kills += m.Kills
+		s.Totals.Deaths += m.Deaths
+		s.Totals.Assists += m.Assists
+		s.Totals.Damage += m.Damage
+		s.Totals.Objectives += m.Objectives
+		s.Totals.Minutes += m.Duration.Minutes()
+	}
+	s.WinRate = percent(s.Wins, s.Matches)
+	s.KDRatio = float64(s.Totals.Kills) / math.Max(1, float64(s.Totals.Deaths))
+	s.KDA = kda(s.Totals.Kills, s.Totals.Deaths, s.Totals.Assists)
+	s.PerMinute = Rates{
+		Kills:      ratio(float64(s.Totals.Kills), s.Totals.Minutes),
+		Deaths:     ratio(float64(s.Totals.Deaths), s.Totals.Minutes),
+		Assists:    ratio(float64(s.Totals.Assists), s.Totals.Minutes),
+		Damage:     ratio(float64(s.Totals.Damage), s.Totals.Minutes),
+		Objectives: ratio(float64(s.Totals.Objectives), s.Totals.Minutes),
+	}
+
+	for _, w := range RollingWindows {
+		if len(ordered) < w {
+			break
+		}
+		s.Rolling = append(s.Rolling, rolling(ordered[len(ordered)-w:]))
+	}
+
+	kdas := make([]float64, 0, len(ordered))
+	dpms := make([]float64, 0, len(ordered))
+	for _, m := range ordered {
+		kdas = append(kdas, kda(m.Kills, m.Deaths, m.Assists))
+		dpms = append(dpms, ratio(float64(m.Damage), m.Duration.Minutes()))
+	}
+	s.Consistency = Consistency{KDA: spread(kdas), DamagePerMin: spread(dpms)}
+
+	return s
+}
+
+func rolling(window []match.Match) RollingAverage {
+	var kills, deaths, assists, wins, damage int
+	var minutes float64
+	for _, m := range window {
+		kills += m.Kills
+		deaths += m.Deaths
+		assists += m.Assists
+		damage += m.Damage
+		minutes += m.Duration.Minutes()
+		if m.Result == match.ResultWin {
+			wins++
+		}
+	}
+	return RollingAverage{
+		Window:       len(window),
+		KDA:          kda(kills, deaths, assists),
+		DamagePerMin: ratio(float64(damage), minutes),
+		WinRate:      percent(wins, len(window)),
+	}
+}
+
+func streaks(ordered []match.Match) Streaks {
+	var st Streaks
+	for _, m := range ordered {
+		if m.Result == st.Current {
+			st.CurrentRun++
+		} else {
+			st.Current = m.Result
+			st.CurrentRun = 1
+		}
+		switch st.Current {
+		case match.ResultWin:
+			st.LongestWin = max(st.LongestWin, st.CurrentRun)
+		case match.ResultLoss:
+			st.LongestLoss = max(st.LongestLoss, st.CurrentRun)
+		}
+	}
+	return st
+}
+
+// breakdown groups matches by key; matches with an empty key are left out.
+func breakdown(ordered []match.Match, keyOf func(match.Match) string) []Breakdown {
+	type acc struct {
+		matches, wins, kills, deaths, assists int
+	}
+	groups := make(map[string]*acc)
+	for _, m := range ordered {
+		key := strings.ToLower(strings.TrimSpace(keyOf(m)))
+		if key == "" {
+			continue
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &acc{}
+			groups[key] = g
+		}
+		g.matches++
+		if m.Result == match.ResultWin {
+			g.wins++
+		}
+		g.kills += m.Kills
+		g.deaths += m.Deaths
+		g.assists += m.Assists
+	}
+
+	out := make([]Breakdown, 0, len(groups))
+	for key, g := range groups {
+		out = append(out, Breakdown{
+			Key:     key,
+			Matches: g.matches,
+			Wins:    g.wins,
+			WinRate: percent(g.wins, g.matches),
+			KDA:     kda(g.kills, g.deaths, g.assists),
+		})
+	}
+	// most played first, then alphabetically for stable output
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Matches != out[j].Matches {
+			return out[i].Matches > out[j].Matches
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// spread computes the population mean, standard deviation and coefficient of
+// variation. An empty input yields a zero Spread instead of NaN.
+func spread(values []float64) Spread {
+	if len(values) == 0 {
+		return Spread{}
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	varSum := 0.0
+	for _, v := range values {
+		varSum += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(varSum / float64(len(values)))
+	return Spread{Mean: mean, StdDev: stdDev, CoefficientOfVariation: ratio(stdDev, mean)}
+}
+
+func kda(kills, deaths, assists int) float64 {
+	return float64(kills+assists) / math.Max(1, float64(deaths))
+}
+
+func percent(part, whole int) float64 {
+	return 100 * ratio(float64(part), float64(whole))
+}
+
+func ratio(num, den float64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import "github.com/compiai/engine/internal/core/domain/match"
+
+// Summary is the game-agnostic performance profile computed from a player's matches.
+// Every field is well-defined for an empty history: counts and rates are zero
+// and slices are empty.
+type Summary struct {
+	Matches     int              `json:"matches"`
+	Wins        int              `json:"wins"`
+	Losses      int              `json:"losses"`
+	Draws       int              `json:"draws"`
+	WinRate     float64          `json:"winRate"` // percent of matches won
+	Totals      Totals           `json:"totals"`
+	KDRatio     float64          `json:"kdRatio"`
+	KDA         float64          `json:"kda"` // (kills + assists) / deaths, deaths floored at 1
+	PerMinute   Rates            `json:"perMinute"`
+	Rolling     []RollingAverage `json:"rolling"`
+	Consistency Consistency      `json:"consistency"`
+	Streaks     Streaks          `json:"streaks"`
+	ByRole      []Breakdown      `json:"byRole"`
+	ByMap       []Breakdown      `json:"byMap"`
+}
+
+type Totals struct {
+	Kills      int     `json:"kills"`
+	Deaths     int     `json:"deaths"`
+	Assists    int     `json:"assists"`
+	Damage     int     `json:"damage"`
+	Objectives int     `json:"objectives"`
+	Minutes    float64 `json:"minutes"`
+}
+
+type Rates struct {
+	Kills      float64 `json:"kills"`
+	Deaths     float64 `json:"deaths"`
+	Assists    float64 `json:"assists"`
+	Damage     float64 `json:"damage"`
+	Objectives float64 `json:"objectives"`
+}
+
+// RollingAverage aggregates the most recent Window matches.
+type RollingAverage struct {
+	Window       int     `json:"window"`
+	KDA          float64 `json:"kda"`
+	DamagePerMin float64 `json:"damagePerMin"`
+	WinRate      float64 `json:"winRate"`
+}
+
+// Spread describes the dispersion of a per-match value.
+type Spread struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	// CoefficientOfVariation is StdDev / Mean; lower means more consistent.
+	// It is zero when the mean is zero.
+	CoefficientOfVariation float64 `json:"coefficientOfVariation"`
+}
+
+type Consistency struct {
+	KDA          Spread `json:"kda"`
+	DamagePerMin Spread `json:"damagePerMin"`
+}
+
+type Streaks struct {
+	Current     match.Result `json:"current,omitempty"` // result of the ongoing streak
+	CurrentRun  int          `json:"currentRun"`
+	LongestWin  int          `json:"longestWin"`
+	LongestLoss int          `json:"longestLoss"`
+}
+
+// Breakdown is a win-rate slice keyed by role or map.
+type Breakdown struct {
+	Key     string  `json:"key"`
+	Matches int     `json:"matches"`
+	Wins    int     `json:"wins"`
+	WinRate float64 `json:"winRate"`
+	KDA     float64 `json:"kda"`
+}
@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/google/uuid"
+)
+
+// RosterMember identifies a player for team metrics.
+type RosterMember struct {
+	UserID uuid.UUID
+	Name   string
+	Role   string
+}
+
+// TeamSummary is the roster-level performance profile of a team.
+type TeamSummary struct {
+	Members      []MemberSummary `json:"members"`
+	RoleCoverage RoleCoverage    `json:"roleCoverage"`
+	Synergy      Synergy         `json:"synergy"`
+}
+
+// MemberSummary is a compact per-player profile within a team.
+type MemberSummary struct {
+	Name         string  `json:"name"`
+	Role         string  `json:"role"`
+	Matches      int     `json:"matches"`
+	WinRate      float64 `json:"winRate"`
+	KDA          float64 `json:"kda"`
+	DamagePerMin float64 `json:"damagePerMin"`
+	// Consistency is the coefficient of variation of per-match KDA.
+	Consistency float64 `json:"consistency"`
+}
+
+// RoleCoverage compares the roster's roles with a full lineup.
+type RoleCoverage struct {
+	Covered    []string `json:"covered"`
+	Missing    []string `json:"missing"`
+	Duplicated []string `json:"duplicated"` // lineup roles held by more than one member
+}
+
+// Synergy measures how the roster performs when members queue together.
+// Two members played together when their matches share an external match
+// ID and result; members who faced each other are not counted.
+type Synergy struct {
+	SharedMatches int     `json:"sharedMatches"`
+	SharedWinRate float64 `json:"sharedWinRate"`
+	SoloMatches   int     `json:"soloMatches"`
+	SoloWinRate   float64 `json:"soloWinRate"`
+	// WinRateDelta is SharedWinRate - SoloWinRate in percentage points.
+	WinRateDelta float64       `json:"winRateDelta"`
+	Pairs        []PairSynergy `json:"pairs"`
+}
+
+// PairSynergy is the record of two members in matches they played together.
+type PairSynergy struct {
+	Members [2]string `json:"members"`
+	Matches int       `json:"matches"`
+	Wins    int       `json:"wins"`
+	WinRate float64   `json:"winRate"`
+}
+
+// ComputeTeam derives a TeamSummary from each member's matches. lineup lists
+// the roles of a full lineup; it may be empty for games without one.
+func ComputeTeam(roster []RosterMember, matches map[uuid.UUID][]match.Match, lineup []string) TeamSummary {
+	t := TeamSummary{
+		Members:      make([]MemberSummary, 0, len(roster)),
+		RoleCoverage: roleCoverage(roster, lineup),
+		Synergy:      synergy(roster, matches),
+	}
+	for _, m := range roster {
+		s := Compute(matches[m.UserID])
+		t.Members = append(t.Members, MemberSummary{
+			Name:         m.Name,
+			Role:         m.Role,
+			Matches:      s.Matches,
+			WinRate:      s.WinRate,
+			KDA:          s.KDA,
+			DamagePerMin: s.PerMinute.Damage,
+			Consistency:  s.Consistency.KDA.CoefficientOfVariation,
+		})
+	}
+	return t
+}
+
+func roleCoverage(roster []RosterMember, lineup []string) RoleCoverage {
+	counts := make(map[string]int, len(roster))
+	for _, m := range roster {
+		counts[m.Role]++
+	}
+	c := RoleCoverage{Covered: []string{}, Missing: []string{}, Duplicated: []string{}}
+	for _, role := range lineup {
+		switch n := counts[role]; {
+		case n == 0:
+			c.Missing = append(c.Missing, role)
+		case n > 1:
+			c.Duplicated = append(c.Duplicated, role)
+			fallthrough
+		default:
+			c.Covered = append(c.Covered, role)
+		}
+	}
+	return c
+}
+
+// played is one roster member's result in a match.
+type played struct {
+	member int // index into the roster
+	result match.Result
+}
+
+func sameResult(ps []played) bool {
+	for _, p := range ps[1:] {
+		if p.result != ps[0].result {
+			return false
+		}
+	}
+	return true
+}
+
+func synergy(roster []RosterMember, matches map[uuid.UUID][]match.Match) Synergy {
+	type key struct{ game, externalID string }
+	games := make(map[key][]played)
+	for i, m := range roster {
+		for _, mt := range matches[m.UserID] {
+			k := key{strings.ToLower(mt.Game), mt.ExternalID}
+			games[k] = append(games[k], played{member: i, result: mt.Result})
+		}
+	}
+
+	type pairKey [2]int
+	pairs := make(map[pairKey]*PairSynergy)
+	var s Synergy
+	var sharedWins, soloWins int
+	for _, ps := range games {
+		if len(ps) < 2 || !sameResult(ps) {
+			// alone, or members on opposing sides: count as solo play
+			for _, p := range ps {
+				s.SoloMatches++
+				if p.result == match.ResultWin {
+					soloWins++
+				}
+			}
+			continue
+		}
+		won := ps[0].result == match.ResultWin
+		s.SharedMatches++
+		if won {
+			sharedWins++
+		}
+		for a := 0; a < len(ps); a++ {
+			for b := a + 1; b < len(ps); b++ {
+				i, j := ps[a].member, ps[b].member
+				if i > j {
+					i, j = j, i
+				}
+				p, ok := pairs[pairKey{i, j}]
+				if !ok {
+					p = &PairSynergy{Members: [2]string{roster[i].Name, roster[j].Name}}
+					pairs[pairKey{i, j}] = p
+				}
+				p.Matches++
+				if won {
+					p.Wins++
+				}
+			}
+		}
+	}
+	s.SharedWinRate = percent(sharedWins, s.SharedMatches)
+	s.SoloWinRate = percent(soloWins, s.SoloMatches)
+	if s.SharedMatches > 0 && s.SoloMatches > 0 {
+		s.WinRateDelta = s.SharedWinRate - s.SoloWinRate
+	}
+
+	s.Pairs = make([]PairSynergy, 0, len(pairs))
+	for _, p := range pairs {
+		p.WinRate = percent(p.Wins, p.Matches)
+		s.Pairs = append(s.Pairs, *p)
+	}
+	// most played pairs first, then by name for stable output
+	sort.Slice(s.Pairs, func(i, j int) bool {
+		if s.Pairs[i].Matches != s.Pairs[j].Matches {
+			return s.Pairs[i].Matches > s.Pairs[j].Matches
+		}
+		return s.Pairs[i].Members[0]+s.Pairs[i].Members[1] < s.Pairs[j].Members[0]+s.Pairs[j].Members[1]
+	})
+	return s
+}
@@ -0,0 +1,74 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrExceeded        = errors.New("quota exceeded")
+	ErrInvalidOverride = errors.New("invalid quota override")
+)
+
+// Limit names reported in ExceededError.
+const (
+	LimitDailyAnalyses = "dailyAnalyses"
+	LimitMonthlyTokens = "monthlyTokens"
+)
+
+// Limits caps a user's LLM consumption. Zero means unlimited.
+type Limits struct {
+	DailyAnalyses int `yaml:"dailyAnalyses"`
+	MonthlyTokens int `yaml:"monthlyTokens"`
+}
+
+// Override replaces the default limits for a single user. Nil fields keep
+// the default.
+type Override struct {
+	UserID        uuid.UUID
+	DailyAnalyses *int
+	MonthlyTokens *int
+	Reason        string // why an admin granted the override
+	UpdatedAt     time.Time
+}
+
+// Validate rejects negative limits; zero means unlimited.
+func (o Override) Validate() error {
+	if o.DailyAnalyses != nil && *o.DailyAnalyses < 0 {
+		return fmt.Errorf("%w: dailyAnalyses must not be negative", ErrInvalidOverride)
+	}
+	if o.MonthlyTokens != nil && *o.MonthlyTokens < 0 {
+		return fmt.Errorf("%w: monthlyTokens must not be negative", ErrInvalidOverride)
+	}
+	return nil
+}
+
+// apply returns defaults with the override's fields applied.
+func (o Override) apply(defaults Limits) Limits {
+	if o.DailyAnalyses != nil {
+		defaults.DailyAnalyses = *o.DailyAnalyses
+	}
+	if o.MonthlyTokens != nil {
+		defaults.MonthlyTokens = *o.MonthlyTokens
+	}
+	return defaults
+}
+
+// ExceededError reports which limit was hit and when it resets.
+type ExceededError struct {
+	Limit   string
+	Used    int
+	Max     int
+	ResetAt time.Time
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota exceeded (%d/%d), resets at %s", e.Limit, e.Used, e.Max, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *ExceededError) Unwrap() error {
+	return ErrExceeded
+}
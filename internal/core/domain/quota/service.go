@@ -0,0 +1,106 @@
+package quota
+
+import (
+	"context"
+	"github.com/compiai/engine/internal/core/domain/usage"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+type Service interface {
+	// Reserve checks the user's limits and, if they allow it, counts one
+	// analysis against the daily quota. It returns an *ExceededError when
+	// a limit is hit.
+	Reserve(ctx context.Context, userID uuid.UUID) error
+	// Limits returns the effective limits of a user.
+	Limits(ctx context.Context, userID uuid.UUID) (Limits, error)
+	// SetOverride replaces the user's override, returning
+	// ErrInvalidOverride for negative limits.
+	SetOverride(ctx context.Context, override Override) error
+	RemoveOverride(ctx context.Context, userID uuid.UUID) error
+}
+
+type service struct {
+	logger       *slog.Logger
+	quotaStorage Storage
+	usageService usage.Service
+	defaults     Limits
+	now          func() time.Time
+}
+
+func NewService(logger *slog.Logger, quotaStorage Storage, usageService usage.Service, defaults Limits) Service {
+	return &service{
+		logger:       logger.WithGroup("core-quota-service"),
+		quotaStorage: quotaStorage,
+		usageService: usageService,
+		defaults:     defaults,
+		now:          time.Now,
+	}
+}
+
+func (s *service) Reserve(ctx context.Context, userID uuid.UUID) error {
+	limits, err := s.Limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	now := s.now().UTC()
+
+	// The token budget is checked first so a user out of tokens does not
+	// also burn an analysis slot.
+	if limits.MonthlyTokens > 0 {
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		nextMonth := monthStart.AddDate(0, 1, 0)
+		totals, err := s.usageService.Totals(ctx, userID, monthStart, nextMonth)
+		if err != nil {
+			return err
+		}
+		if totals.Tokens() >= limits.MonthlyTokens {
+			return &ExceededError{Limit: LimitMonthlyTokens, Used: totals.Tokens(), Max: limits.MonthlyTokens, ResetAt: nextMonth}
+		}
+	}
+
+	day := usage.Day(now)
+	count, ok, err := s.quotaStorage.ReserveAnalysis(ctx, userID, day, limits.DailyAnalyses)
+	if err != nil {
+		s.logger.Error("reserve analysis failed", "error", err, "userId", userID)
+		return err
+	}
+	if !ok {
+		return &ExceededError{Limit: LimitDailyAnalyses, Used: count, Max: limits.DailyAnalyses, ResetAt: day.AddDate(0, 0, 1)}
+	}
+	return nil
+}
+
+func (s *service) Limits(ctx context.Context, userID uuid.UUID) (Limits, error) {
+	override, err := s.quotaStorage.FindOverride(ctx, userID)
+	if err != nil {
+		return Limits{}, err
+	}
+	if override == nil {
+		return s.defaults, nil
+	}
+	return override.apply(s.defaults), nil
+}
+
+func (s *service) SetOverride(ctx context.Context, override Override) error {
+	if err := override.Validate(); err != nil {
+		return err
+	}
+	override.UpdatedAt = s.now().UTC()
+	if err := s.quotaStorage.SaveOverride(ctx, override); err != nil {
+		s.logger.Error("save quota override failed", "error", err, "userId", override.UserID)
+		return err
+	}
+	s.logger.Info("quota override set", "userId", override.UserID, "reason", override.Reason)
+	return nil
+}
+
+func (s *service) RemoveOverride(ctx context.Context, userID uuid.UUID) error {
+	if err := s.quotaStorage.DeleteOverride(ctx, userID); err != nil {
+		s.logger.Error("delete quota override failed", "error", err, "userId", userID)
+		return err
+	}
+	s.logger.Info("quota override removed", "userId", userID)
+	return nil
+}
@@ -0,0 +1,19 @@
+package quota
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Storage interface {
+	// FindOverride returns the user's override, or nil if there is none.
+	FindOverride(ctx context.Context, userID uuid.UUID) (*Override, error)
+	SaveOverride(ctx context.Context, override Override) error
+	DeleteOverride(ctx context.Context, userID uuid.UUID) error
+
+	// ReserveAnalysis atomically increments the user's analysis counter for
+	// day unless it already reached limit (limit <= 0 means unlimited). It
+	// returns the counter value and whether the reservation succeeded.
+	ReserveAnalysis(ctx context.Context, userID uuid.UUID, day time.Time, limit int) (count int, ok bool, err error)
+}
@@ -0,0 +1,82 @@
+package team
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTeam  = errors.New("invalid team")
+	ErrTeamNotFound = errors.New("team not found")
+	ErrRosterFull   = errors.New("roster is full")
+	// ErrNoConsent is returned when adding a player who has not granted the
+	// team owner access to their stats.
+	ErrNoConsent = errors.New("player has not granted the team owner access to their stats")
+)
+
+const (
+	maxNameLength = 64
+	// MaxRosterSize caps a roster including substitutes.
+	MaxRosterSize = 10
+)
+
+// Team is a roster of players competing together in one game.
+type Team struct {
+	ID        uuid.UUID
+	Name      string
+	Game      string
+	OwnerID   uuid.UUID // the team admin who manages the roster
+	Roster    []Member
+	CreatedAt time.Time
+}
+
+// Member is a player on a team's roster.
+type Member struct {
+	UserID   uuid.UUID
+	Role     string // in-game role, canonicalized by the game plugin when known
+	JoinedAt time.Time
+}
+
+// HasMember reports whether userID is on the roster.
+func (t Team) HasMember(userID uuid.UUID) bool {
+	for _, m := range t.Roster {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// MemberIDs returns the user IDs of the roster.
+func (t Team) MemberIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(t.Roster))
+	for _, m := range t.Roster {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+type NewTeam struct {
+	Name    string
+	Game    string
+	OwnerID uuid.UUID
+}
+
+// Validate checks the fields required to create a team.
+func (t NewTeam) Validate() error {
+	name := strings.TrimSpace(t.Name)
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidTeam)
+	case len(name) > maxNameLength:
+		return fmt.Errorf("%w: name exceeds %d characters", ErrInvalidTeam, maxNameLength)
+	case strings.TrimSpace(t.Game) == "":
+		return fmt.Errorf("%w: game is required", ErrInvalidTeam)
+	case t.OwnerID == uuid.Nil:
+		return fmt.Errorf("%w: ownerId is required", ErrInvalidTeam)
+	}
+	return nil
+}
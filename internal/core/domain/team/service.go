@@ -0,0 +1,149 @@
+package team
+
+import (
+	"context"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/game"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Filter selects teams by ID. OwnerIDs and MemberIDs are alternatives: a
+// team matches if it is owned by one of OwnerIDs or has one of MemberIDs on
+// its roster.
+type Filter struct {
+	IDs       []uuid.UUID
+	OwnerIDs  []uuid.UUID
+	MemberIDs []uuid.UUID
+}
+
+type Service interface {
+	Create(ctx context.Context, newTeam NewTeam) (Team, error)
+	FindOne(ctx context.Context, id uuid.UUID) (Team, error)
+	Find(ctx context.Context, filter Filter) ([]Team, error)
+	// AddMember puts a user on the roster, or changes their role if they
+	// already are. Players other than the owner must have granted the owner
+	// access.ScopeViewStats, or ErrNoConsent is returned.
+	AddMember(ctx context.Context, teamID, userID uuid.UUID, role string) (Team, error)
+	RemoveMember(ctx context.Context, teamID, userID uuid.UUID) error
+}
+
+type service struct {
+	logger      *slog.Logger
+	teamStorage Storage
+	userService user.Service
+	gamePlugins *game.Registry
+	grants      access.GrantChecker
+}
+
+// NewService creates a team Service. grants records the consent players
+// gave team owners; a roster can only hold players who granted the owner
+// access.ScopeViewStats.
+func NewService(
+	logger *slog.Logger,
+	teamStorage Storage,
+	userService user.Service,
+	plugins *game.Registry,
+	grants access.GrantChecker,
+) Service {
+	return &service{
+		logger:      logger.WithGroup("core-team-service"),
+		teamStorage: teamStorage,
+		userService: userService,
+		gamePlugins: plugins,
+		grants:      grants,
+	}
+}
+
+func (s *service) Create(ctx context.Context, newTeam NewTeam) (Team, error) {
+	if err := newTeam.Validate(); err != nil {
+		return Team{}, err
+	}
+	// store the canonical title so roster analysis finds the plugin
+	title := strings.ToLower(strings.TrimSpace(newTeam.Game))
+	if p, ok := s.gamePlugins.Lookup(title); ok {
+		title = p.Title()
+	}
+	t := Team{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(newTeam.Name),
+		Game:      title,
+		OwnerID:   newTeam.OwnerID,
+		Roster:    []Member{},
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.teamStorage.Save(ctx, t); err != nil {
+		s.logger.Error("create team failed", "error", err)
+		return Team{}, err
+	}
+	s.logger.Info("team created", "teamId", t.ID, "ownerId", t.OwnerID, "game", t.Game)
+	return t, nil
+}
+
+func (s *service) FindOne(ctx context.Context, id uuid.UUID) (Team, error) {
+	return s.teamStorage.FindOneByID(ctx, id)
+}
+
+func (s *service) Find(ctx context.Context, filter Filter) ([]Team, error) {
+	return s.teamStorage.Find(ctx, filter)
+}
+
+func (s *service) AddMember(ctx context.Context, teamID, userID uuid.UUID, role string) (Team, error) {
+	t, err := s.teamStorage.FindOneByID(ctx, teamID)
+	if err != nil {
+		return Team{}, err
+	}
+	if !t.HasMember(userID) && len(t.Roster) >= MaxRosterSize {
+		return Team{}, ErrRosterFull
+	}
+	if _, err := s.userService.FindOne(ctx, user.SingleFilter{ID: &userID}); err != nil {
+		return Team{}, err
+	}
+	if userID != t.OwnerID {
+		ok, err := s.grants.HasGrant(ctx, t.OwnerID, userID, access.ScopeViewStats)
+		if err != nil {
+			return Team{}, err
+		}
+		if !ok {
+			return Team{}, ErrNoConsent
+		}
+	}
+	role, err = s.canonicalRole(t.Game, role)
+	if err != nil {
+		return Team{}, err
+	}
+
+	member := Member{UserID: userID, Role: role, JoinedAt: time.Now().UTC()}
+	if err := s.teamStorage.SaveMember(ctx, teamID, member); err != nil {
+		s.logger.Error("save team member failed", "error", err, "teamId", teamID)
+		return Team{}, err
+	}
+	return s.teamStorage.FindOneByID(ctx, teamID)
+}
+
+func (s *service) RemoveMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	return s.teamStorage.DeleteMember(ctx, teamID, userID)
+}
+
+// canonicalRole resolves role through the team game's plugin. Roles are
+// free-form for games without a plugin.
+func (s *service) canonicalRole(title, role string) (string, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		return "", fmt.Errorf("%w: role is required", ErrInvalidTeam)
+	}
+	p, ok := s.gamePlugins.Lookup(title)
+	if !ok {
+		return role, nil
+	}
+	canonical, ok := p.CanonicalRole(role)
+	if !ok {
+		return "", fmt.Errorf("%w: unknown %s role %q, expected one of %s",
+			ErrInvalidTeam, p.Title(), role, strings.Join(p.Roles(), ", "))
+	}
+	return canonical, nil
+}
@@ -0,0 +1,19 @@
+package team
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type Storage interface {
+	// Save stores the team's name, game and owner; the roster is managed
+	// with SaveMember and DeleteMember.
+	Save(ctx context.Context, team Team) error
+	// FindOneByID returns the team with its roster, or ErrTeamNotFound.
+	FindOneByID(ctx context.Context, id uuid.UUID) (Team, error)
+	Find(ctx context.Context, filter Filter) ([]Team, error)
+	// SaveMember adds a member or updates their role.
+	SaveMember(ctx context.Context, teamID uuid.UUID, member Member) error
+	// DeleteMember returns ErrTeamNotFound if the user was not on the roster.
+	DeleteMember(ctx context.Context, teamID, userID uuid.UUID) error
+}
@@ -0,0 +1,38 @@
+package usage
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Record aggregates the LLM usage of a user for one UTC day and model.
+type Record struct {
+	UserID           uuid.UUID
+	Day              time.Time // midnight UTC
+	Provider         string
+	Model            string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	CostUSD          float64 // zero for models missing from the price table
+}
+
+// Totals sums records over a period.
+type Totals struct {
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	CostUSD          float64
+}
+
+// Tokens returns prompt and completion tokens combined.
+func (t Totals) Tokens() int {
+	return t.PromptTokens + t.CompletionTokens
+}
+
+// Day truncates t to midnight UTC.
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,84 @@
+package usage
+
+import (
+	"context"
+	"github.com/compiai/engine/pkg/llm"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+type Filter struct {
+	UserIDs []uuid.UUID
+	From    *time.Time // inclusive lower bound on Day
+	To      *time.Time // exclusive upper bound on Day
+}
+
+type Service interface {
+	// Record persists the usage of one LLM call made on behalf of userID.
+	Record(ctx context.Context, userID uuid.UUID, u llm.Usage) error
+	Find(ctx context.Context, filter Filter) ([]Record, error)
+	// Totals sums a user's usage for days in [from, to).
+	Totals(ctx context.Context, userID uuid.UUID, from, to time.Time) (Totals, error)
+}
+
+type service struct {
+	logger       *slog.Logger
+	usageStorage Storage
+	prices       llm.PriceTable
+	now          func() time.Time
+}
+
+func NewService(logger *slog.Logger, usageStorage Storage, prices llm.PriceTable) Service {
+	return &service{
+		logger:       logger.WithGroup("core-usage-service"),
+		usageStorage: usageStorage,
+		prices:       prices,
+		now:          time.Now,
+	}
+}
+
+func (s *service) Record(ctx context.Context, userID uuid.UUID, u llm.Usage) error {
+	cost, priced := s.prices.Cost(u)
+	if !priced {
+		s.logger.Warn("no price configured for model, recording zero cost", "model", u.Model, "provider", u.Provider)
+	}
+	rec := Record{
+		UserID:           userID,
+		Day:              Day(s.now()),
+		Provider:         u.Provider,
+		Model:            u.Model,
+		Requests:         1,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		CostUSD:          cost,
+	}
+	if err := s.usageStorage.Add(ctx, rec); err != nil {
+		s.logger.Error("record usage failed", "error", err, "userId", userID)
+		return err
+	}
+	s.logger.Info("llm usage",
+		"userId", userID, "provider", u.Provider, "model", u.Model,
+		"promptTokens", u.PromptTokens, "completionTokens", u.CompletionTokens,
+		"costUsd", cost, "latency", u.Latency)
+	return nil
+}
+
+func (s *service) Find(ctx context.Context, filter Filter) ([]Record, error) {
+	return s.usageStorage.Find(ctx, filter)
+}
+
+func (s *service) Totals(ctx context.Context, userID uuid.UUID, from, to time.Time) (Totals, error) {
+	records, err := s.usageStorage.Find(ctx, Filter{UserIDs: []uuid.UUID{userID}, From: &from, To: &to})
+	if err != nil {
+		return Totals{}, err
+	}
+	var t Totals
+	for _, r := range records {
+		t.Requests += r.Requests
+		t.PromptTokens += r.PromptTokens
+		t.CompletionTokens += r.CompletionTokens
+		t.CostUSD += r.CostUSD
+	}
+	return t, nil
+}
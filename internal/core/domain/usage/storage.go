@@ -0,0 +1,11 @@
+package usage
+
+import "context"
+
+type Storage interface {
+	// Add increments the stored record with the same (UserID, Day, Provider,
+	// Model) by the counters of rec, creating it if needed.
+	Add(ctx context.Context, rec Record) error
+
+	Find(ctx context.Context, filter Filter) ([]Record, error)
+}
@@ -0,0 +1,142 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// SortKey orders a user listing. Ties are broken by user ID so that every
+// ordering is total and cursors are stable.
+type SortKey string
+
+const (
+	SortUsername  SortKey = "username"
+	SortCreatedAt SortKey = "createdAt"
+)
+
+func (k SortKey) Valid() bool {
+	return k == SortUsername || k == SortCreatedAt
+}
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+// ListQuery selects one page of users matching Filter. An empty Filter
+// lists every user.
+type ListQuery struct {
+	Filter Filter
+	Sort   SortKey // defaults to SortUsername
+	Desc   bool
+	Limit  int    // defaults to DefaultListLimit, at most MaxListLimit
+	Cursor string // NextCursor of the previous page; empty for the first page
+}
+
+// Page is one window of a user listing.
+type Page struct {
+	Users []User
+	// NextCursor continues the listing after the last user of this page;
+	// it is empty on the last page.
+	NextCursor string
+	// Total counts every user matching the filter, across all pages.
+	Total int
+}
+
+// StorageQuery is the decoded form of a ListQuery handed to Storage.List.
+type StorageQuery struct {
+	Filter Filter
+	Sort   SortKey
+	Desc   bool
+	After  *Cursor // position to continue after; nil for the first page
+	Limit  int
+}
+
+// Cursor is the position of the last user of a page in its sort order.
+type Cursor struct {
+	Sort  SortKey   `json:"s"`
+	Desc  bool      `json:"d,omitempty"`
+	Value string    `json:"v"` // the sort key's value, see SortValue
+	ID    uuid.UUID `json:"i"`
+}
+
+// SortValue returns the value u is ordered by under key, in the form kept
+// in cursors. Usernames compare bytewise, timestamps chronologically.
+func SortValue(u User, key SortKey) string {
+	if key == SortCreatedAt {
+		return u.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return u.Username
+}
+
+// EncodeCursor returns the opaque form of c handed to clients.
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor, returning
+// ErrInvalidFilter if it is malformed.
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(raw, &c) != nil || !c.Sort.Valid() {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	if c.Sort == SortCreatedAt {
+		if _, err := time.Parse(time.RFC3339Nano, c.Value); err != nil {
+			return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+		}
+	}
+	return c, nil
+}
+
+// storageQuery validates q, applies its defaults and decodes its cursor.
+func (q ListQuery) storageQuery() (StorageQuery, error) {
+	sq := StorageQuery{Filter: q.Filter.normalized(), Sort: q.Sort, Desc: q.Desc, Limit: q.Limit}
+	if sq.Sort == "" {
+		sq.Sort = SortUsername
+	}
+	if !sq.Sort.Valid() {
+		return StorageQuery{}, fmt.Errorf("%w: unknown sort key %q", ErrInvalidFilter, q.Sort)
+	}
+	switch {
+	case sq.Limit == 0:
+		sq.Limit = DefaultListLimit
+	case sq.Limit < 0 || sq.Limit > MaxListLimit:
+		return StorageQuery{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MaxListLimit)
+	}
+	if q.Cursor != "" {
+		c, err := DecodeCursor(q.Cursor)
+		if err != nil {
+			return StorageQuery{}, err
+		}
+		if c.Sort != sq.Sort || c.Desc != sq.Desc {
+			return StorageQuery{}, fmt.Errorf("%w: cursor belongs to a different sort order", ErrInvalidFilter)
+		}
+		sq.After = &c
+	}
+	return sq, nil
+}
+
+// normalized lowercases the game names, which are stored lowercase.
+func (f Filter) normalized() Filter {
+	if len(f.Games) > 0 {
+		games := make([]string, 0, len(f.Games))
+		for _, g := range f.Games {
+			games = append(games, strings.ToLower(strings.TrimSpace(g)))
+		}
+		f.Games = games
+	}
+	return f
+}
+
+// IsEmpty reports whether f sets no criteria.
+func (f Filter) IsEmpty() bool {
+	return len(f.IDs) == 0 && len(f.Usernames) == 0 && len(f.SolanaWallets) == 0 &&
+		f.UsernamePrefix == "" && f.UsernameContains == "" && len(f.Games) == 0
+}
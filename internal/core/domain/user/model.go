@@ -1,6 +1,52 @@
 package user
 
-import "github.com/google/uuid"
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Storage errors, returned by every Storage implementation.
+var (
+	ErrNotFound = errors.New("user not found")
+	// ErrConflict is matched by every error reporting a violated uniqueness
+	// or integrity constraint, such as ErrUsernameTaken.
+	ErrConflict      = errors.New("conflicting user data")
+	ErrInvalidFilter = errors.New("invalid user filter")
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidUser        = errors.New("invalid user")
+	ErrUsernameTaken      = conflictError("username already taken")
+	ErrWalletTaken        = conflictError("solana wallet already registered")
+	ErrInvalidWallet      = errors.New("invalid solana wallet public key")
+	ErrInvalidChallenge   = errors.New("invalid or expired wallet challenge")
+	ErrInvalidSignature   = errors.New("invalid wallet signature")
+	ErrInvalidRole        = errors.New("invalid role")
+)
+
+// conflictError names the constraint a write violated; it matches ErrConflict.
+type conflictError string
+
+func (e conflictError) Error() string { return string(e) }
+
+func (e conflictError) Is(target error) bool { return target == ErrConflict }
+
+const (
+	minPasswordLength = 8
+	// maxPasswordLength is bcrypt's input limit; longer passwords would be
+	// silently truncated.
+	maxPasswordLength = 72
+)
+
+// usernamePattern allows 3-32 letters, digits, '_', '.' and '-'.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
 
 type User struct {
 	ID                    uuid.UUID
@@ -8,12 +54,149 @@ type User struct {
 	SolanaWalletPublicKey string
 	PasswordHash          string
 	Games                 []string // list of games player's interested in
+	Roles                 []Role
+	CreatedAt             time.Time
+}
+
+// Role grants a class of permissions; a user may hold several. Coaches and
+// team admins invite players and act on the data players granted them
+// access to; both may create teams.
+type Role string
+
+const (
+	RolePlayer        Role = "player"
+	RoleCoach         Role = "coach"
+	RoleTeamAdmin     Role = "team_admin"
+	RolePlatformAdmin Role = "platform_admin"
+)
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	switch r {
+	case RolePlayer, RoleCoach, RoleTeamAdmin, RolePlatformAdmin:
+		return true
+	}
+	return false
 }
 
+// defaultRoles are assigned to every new user.
+func defaultRoles() []Role {
+	return []Role{RolePlayer}
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID uuid.UUID
+	Roles  []Role
+}
+
+// HasRole reports whether the principal holds any of roles.
+func (p Principal) HasRole(roles ...Role) bool {
+	for _, have := range p.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewUser signs up with a password. Wallets are linked afterwards with
+// AuthService.LinkWallet, which proves ownership with a signed challenge.
 type NewUser struct {
-	Username              string
-	SolanaWalletPublicKey string
-	PasswordHash          string
+	Username string
+	Password string // plaintext, hashed by Service.Register
+}
+
+// Validate applies the username and password policy.
+func (u NewUser) Validate() error {
+	if err := validateUsername(u.Username); err != nil {
+		return err
+	}
+	return validatePassword(u.Password)
+}
+
+func validateUsername(username string) error {
+	switch {
+	case !usernamePattern.MatchString(username):
+		return fmt.Errorf("%w: username must be 3-32 letters, digits, '_', '.' or '-'", ErrInvalidUser)
+	case strings.HasPrefix(strings.ToLower(username), walletUsernamePrefix):
+		return fmt.Errorf("%w: the %q prefix is reserved for wallet accounts", ErrInvalidUser, walletUsernamePrefix)
+	}
+	return nil
+}
+
+func validatePassword(password string) error {
+	switch {
+	case len(password) < minPasswordLength:
+		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidUser, minPasswordLength)
+	case len(password) > maxPasswordLength:
+		return fmt.Errorf("%w: password must be at most %d bytes", ErrInvalidUser, maxPasswordLength)
+	case !hasLetterAndDigit(password):
+		return fmt.Errorf("%w: password must contain a letter and a digit", ErrInvalidUser)
+	}
+	return nil
+}
+
+// UserUpdate changes a user's profile. Nil fields are left unchanged.
+// Wallets are linked with AuthService.LinkWallet, which requires a signed
+// challenge proving ownership.
+type UserUpdate struct {
+	Username *string
+	// Games replaces the games the user is interested in. Names are
+	// trimmed, lowercased and deduplicated.
+	Games *[]string
+}
+
+// apply returns u with the update applied and validated.
+func (up UserUpdate) apply(u User) (User, error) {
+	if up.Username != nil {
+		if err := validateUsername(*up.Username); err != nil {
+			return User{}, err
+		}
+		u.Username = *up.Username
+	}
+	if up.Games != nil {
+		games, err := normalizeGames(*up.Games)
+		if err != nil {
+			return User{}, err
+		}
+		u.Games = games
+	}
+	return u, nil
+}
+
+// maxGames caps the games a user can list.
+const maxGames = 32
+
+func normalizeGames(games []string) ([]string, error) {
+	if len(games) > maxGames {
+		return nil, fmt.Errorf("%w: at most %d games", ErrInvalidUser, maxGames)
+	}
+	out := make([]string, 0, len(games))
+	seen := make(map[string]bool, len(games))
+	for _, g := range games {
+		g = strings.ToLower(strings.TrimSpace(g))
+		if g == "" {
+			return nil, fmt.Errorf("%w: game names must not be empty", ErrInvalidUser)
+		}
+		if seen[g] {
+			continue
+		}
+		seen[g] = true
+		out = append(out, g)
+	}
+	return out, nil
+}
+
+func hasLetterAndDigit(s string) bool {
+	var letter, digit bool
+	for _, r := range s {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	return letter && digit
 }
 
 type Credentials struct {
@@ -25,3 +208,38 @@ type TokenPair struct {
 	AccessToken  string
 	RefreshToken string
 }
+
+// Token types carried in the "typ" claim.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// RefreshToken is the server-side record of an issued refresh token. Every
+// token obtained by refreshing inherits the FamilyID of the login it
+// descends from, so a whole session can be revoked at once.
+type RefreshToken struct {
+	ID        uuid.UUID // the token's jti
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time // set once the token was exchanged
+	RevokedAt *time.Time
+}
+
+// WalletChallenge is a single-use message a wallet owner signs to log in.
+type WalletChallenge struct {
+	Nonce     string
+	Wallet    string // base58 public key the challenge was issued for
+	Message   string // exact text the wallet must sign
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// WalletSignature answers a WalletChallenge.
+type WalletSignature struct {
+	Wallet    string
+	Nonce     string
+	Signature string // base58 ed25519 signature of the challenge message
+}
@@ -1,9 +1,10 @@
 package user
 
 import (
-	""
 	"context"
 	"errors"
+	"fmt"
+	"github.com/compiai/engine/pkg/jwk"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -17,21 +18,67 @@ type SingleFilter struct {
 	SolanaWallet *string
 }
 
+// Filter selects users. Criteria are AND-combined; the values within one
+// criterion are alternatives.
 type Filter struct {
 	IDs           []uuid.UUID
 	Usernames     []string
 	SolanaWallets []string
+	// UsernamePrefix and UsernameContains match usernames case-insensitively.
+	UsernamePrefix   string
+	UsernameContains string
+	// Games matches users interested in any of the games.
+	Games []string
 }
 
 type Service interface {
-	Register(ctx context.Context, newUser NewUser) error
+	// Register validates newUser, hashes its password and stores it,
+	// returning the new user's ID.
+	Register(ctx context.Context, newUser NewUser) (uuid.UUID, error)
 	FindOne(ctx context.Context, filter SingleFilter) (User, error)
+	// Find returns the users matching filter, or none if filter is empty.
 	Find(ctx context.Context, filter Filter) ([]User, error)
+	// List returns one page of the users matching query.Filter in the
+	// requested order, or ErrInvalidFilter for a bad sort, limit or cursor.
+	List(ctx context.Context, query ListQuery) (Page, error)
+	// SetRoles replaces the roles of a user. New tokens carry the new roles;
+	// tokens already issued keep theirs until they expire.
+	SetRoles(ctx context.Context, id uuid.UUID, roles []Role) error
+	// Update applies update to the user's profile and returns the result.
+	Update(ctx context.Context, id uuid.UUID, update UserUpdate) (User, error)
+	// ChangePassword replaces the user's password after checking the
+	// current one, returning ErrInvalidCredentials if it does not match.
+	// Users who signed up with a wallet have no password and set their
+	// first one with an empty current password.
+	ChangePassword(ctx context.Context, id uuid.UUID, current, next string) error
+	// Delete erases the user and, through storage, their matches, usage,
+	// quotas, tokens, coaching links, team memberships and owned teams.
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type AuthService interface {
 	GenerateTokenPair(ctx context.Context, creds Credentials) (TokenPair, error)
+	// Refresh exchanges a refresh token for a new pair. Each refresh token
+	// is single-use: presenting one again revokes its whole family.
 	Refresh(ctx context.Context, refreshToken string) (TokenPair, error)
+	// Authenticate validates an access token and returns the user and roles
+	// it was issued for.
+	Authenticate(ctx context.Context, accessToken string) (Principal, error)
+	// Logout revokes the session (token family) the refresh token belongs to.
+	Logout(ctx context.Context, refreshToken string) error
+	// LogoutAll revokes every session of the user.
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+	// WalletChallenge issues a single-use message for wallet to sign.
+	WalletChallenge(ctx context.Context, wallet string) (WalletChallenge, error)
+	// VerifyWallet checks a signed challenge and logs the wallet owner in,
+	// creating a passwordless user for wallets seen for the first time.
+	VerifyWallet(ctx context.Context, signed WalletSignature) (TokenPair, error)
+	// LinkWallet checks a signed challenge like VerifyWallet and links the
+	// wallet to the user, returning ErrWalletTaken if another user has it.
+	LinkWallet(ctx context.Context, userID uuid.UUID, signed WalletSignature) (User, error)
+	// UnlinkWallet removes the user's wallet. Users without a password
+	// cannot, as it is their only way to log in.
+	UnlinkWallet(ctx context.Context, userID uuid.UUID) (User, error)
 }
 
 type service struct {
@@ -49,95 +96,354 @@ func NewService(logger *slog.Logger, userStorage Storage) *service {
 // service implements Service and AuthService
 
 type authService struct {
-	logger      *slog.Logger
-	userStorage Storage
-	jwtSecret   []byte
-	accessTTL   time.Duration
-	refreshTTL  time.Duration
+	logger           *slog.Logger
+	userStorage      Storage
+	tokenStorage     TokenStorage
+	challengeStorage ChallengeStorage
+	keys             *jwk.Set
+	accessTTL        time.Duration
+	refreshTTL       time.Duration
 }
 
-// NewAuthService creates a new AuthService with given secret and storage
-func NewAuthService(logger *slog.Logger, storage Storage, jwtSecret []byte) AuthService {
+// tokenClaims are the claims of both token types; Type tells them apart so
+// a refresh token cannot be used as an access token and vice versa.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Type  string `json:"typ"`
+	Roles []Role `json:"roles,omitempty"` // access tokens only
+}
+
+// NewAuthService creates a new AuthService signing with the given key set and storage
+func NewAuthService(
+	logger *slog.Logger,
+	storage Storage,
+	tokenStorage TokenStorage,
+	challengeStorage ChallengeStorage,
+	keys *jwk.Set,
+) AuthService {
 	return &authService{
-		logger:      logger.WithGroup("auth-service"),
-		userStorage: storage,
-		jwtSecret:   jwtSecret,
-		accessTTL:   15 * time.Minute,
-		refreshTTL:  7 * 24 * time.Hour,
+		logger:           logger.WithGroup("auth-service"),
+		userStorage:      storage,
+		tokenStorage:     tokenStorage,
+		challengeStorage: challengeStorage,
+		keys:             keys,
+		accessTTL:        15 * time.Minute,
+		refreshTTL:       7 * 24 * time.Hour,
 	}
 }
 
 func (a *authService) GenerateTokenPair(ctx context.Context, creds Credentials) (TokenPair, error) {
 	// authenticate user
 	user, err := a.userStorage.FindOneByUsername(ctx, creds.Username)
+	if errors.Is(err, ErrNotFound) {
+		// unknown usernames look exactly like wrong passwords to the caller
+		return TokenPair{}, ErrInvalidCredentials
+	}
 	if err != nil {
 		return TokenPair{}, err
 	}
+	if user.PasswordHash == "" {
+		// wallet-only users have no password to log in with
+		return TokenPair{}, ErrInvalidCredentials
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(creds.Password)); err != nil {
-		return TokenPair{}, errors.New("invalid credentials")
+		return TokenPair{}, ErrInvalidCredentials
 	}
-	// generate tokens
-	accessToken, err := a.makeToken(user.ID, a.accessTTL)
+	// a login starts a new token family
+	return a.issue(ctx, user, uuid.New())
+}
+
+func (a *authService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	stored, err := a.lookupRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return TokenPair{}, err
 	}
-	refreshToken, err := a.makeToken(user.ID, a.refreshTTL)
+	now := time.Now().UTC()
+	ok, err := a.tokenStorage.MarkRefreshTokenUsed(ctx, stored.ID, now)
 	if err != nil {
 		return TokenPair{}, err
 	}
-	return TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+	if !ok {
+		// the token was already exchanged (or revoked): someone may hold a
+		// stolen copy, so end the whole session
+		a.logger.Warn("refresh token reuse detected, revoking family",
+			"userId", stored.UserID, "familyId", stored.FamilyID)
+		if err := a.tokenStorage.RevokeRefreshTokenFamily(ctx, stored.FamilyID, now); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrInvalidToken
+	}
+	// reload the user so role changes reach the new access token
+	user, err := a.userStorage.FindOneByID(ctx, stored.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return TokenPair{}, ErrInvalidToken
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return a.issue(ctx, user, stored.FamilyID)
 }
 
-func (a *authService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
-	// parse token
-	claims := &jwt.RegisteredClaims{}
-	tkn, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return a.jwtSecret, nil
-	})
-	if err != nil || !tkn.Valid {
-		return TokenPair{}, errors.New("invalid refresh token")
+func (a *authService) Authenticate(ctx context.Context, accessToken string) (Principal, error) {
+	claims, err := a.parseToken(accessToken, TokenTypeAccess)
+	if err != nil {
+		return Principal{}, err
 	}
 	id, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return TokenPair{}, err
+		return Principal{}, ErrInvalidToken
+	}
+	return Principal{UserID: id, Roles: claims.Roles}, nil
+}
+
+func (a *authService) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := a.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return a.tokenStorage.RevokeRefreshTokenFamily(ctx, stored.FamilyID, time.Now().UTC())
+}
+
+func (a *authService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	if err := a.tokenStorage.RevokeUserRefreshTokens(ctx, userID, time.Now().UTC()); err != nil {
+		a.logger.Error("revoke user sessions failed", "error", err, "userId", userID)
+		return err
+	}
+	a.logger.Info("all sessions revoked", "userId", userID)
+	return nil
+}
+
+func (a *authService) WalletChallenge(ctx context.Context, wallet string) (WalletChallenge, error) {
+	if _, err := decodeWallet(wallet); err != nil {
+		return WalletChallenge{}, err
 	}
-	// issue new tokens
-	accessToken, err := a.makeToken(id, a.accessTTL)
+	challenge, err := newWalletChallenge(wallet, time.Now().UTC())
 	if err != nil {
+		return WalletChallenge{}, err
+	}
+	if err := a.challengeStorage.SaveChallenge(ctx, challenge); err != nil {
+		a.logger.Error("save wallet challenge failed", "error", err)
+		return WalletChallenge{}, err
+	}
+	return challenge, nil
+}
+
+func (a *authService) VerifyWallet(ctx context.Context, signed WalletSignature) (TokenPair, error) {
+	if err := a.verifyWallet(ctx, signed); err != nil {
 		return TokenPair{}, err
 	}
-	newRefresh, err := a.makeToken(id, a.refreshTTL)
+
+	user, err := a.userStorage.FindOneBySolanaWallet(ctx, signed.Wallet)
+	if errors.Is(err, ErrNotFound) {
+		user, err = a.provisionWalletUser(ctx, signed.Wallet)
+	}
 	if err != nil {
 		return TokenPair{}, err
 	}
-	return TokenPair{AccessToken: accessToken, RefreshToken: newRefresh}, nil
+	return a.issue(ctx, user, uuid.New())
 }
 
-func (a *authService) makeToken(userID uuid.UUID, ttl time.Duration) (string, error) {
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Subject:   userID.String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-	})
-	return claims.SignedString(a.jwtSecret)
+func (a *authService) LinkWallet(ctx context.Context, userID uuid.UUID, signed WalletSignature) (User, error) {
+	if err := a.verifyWallet(ctx, signed); err != nil {
+		return User{}, err
+	}
+	user, err := a.userStorage.FindOneByID(ctx, userID)
+	if err != nil {
+		return User{}, err
+	}
+	user.SolanaWalletPublicKey = signed.Wallet
+	if err := a.userStorage.Save(ctx, user); err != nil {
+		a.logger.Error("link wallet failed", "error", err, "userId", userID)
+		return User{}, err
+	}
+	a.logger.Info("wallet linked", "userId", userID)
+	return user, nil
 }
 
-// Service methods (Register, FindOne, Find)
+func (a *authService) UnlinkWallet(ctx context.Context, userID uuid.UUID) (User, error) {
+	user, err := a.userStorage.FindOneByID(ctx, userID)
+	if err != nil {
+		return User{}, err
+	}
+	if user.PasswordHash == "" {
+		return User{}, fmt.Errorf("%w: users without a password cannot unlink their wallet", ErrInvalidUser)
+	}
+	user.SolanaWalletPublicKey = ""
+	if err := a.userStorage.Save(ctx, user); err != nil {
+		a.logger.Error("unlink wallet failed", "error", err, "userId", userID)
+		return User{}, err
+	}
+	a.logger.Info("wallet unlinked", "userId", userID)
+	return user, nil
+}
 
-func (s *service) Register(ctx context.Context, newUser NewUser) error {
-	id := uuid.New()
+// verifyWallet consumes the challenge signed answers and checks that the
+// wallet's owner signed it.
+func (a *authService) verifyWallet(ctx context.Context, signed WalletSignature) error {
+	pub, err := decodeWallet(signed.Wallet)
+	if err != nil {
+		return err
+	}
+	challenge, err := a.challengeStorage.ConsumeChallenge(ctx, signed.Nonce, time.Now().UTC())
+	if errors.Is(err, ErrNotFound) {
+		return ErrInvalidChallenge
+	}
+	if err != nil {
+		return err
+	}
+	if challenge.Wallet != signed.Wallet {
+		return ErrInvalidChallenge
+	}
+	return verifyWalletSignature(pub, challenge.Message, signed.Signature)
+}
+
+// provisionWalletUser creates a passwordless user owning wallet, who can
+// only log in by signing wallet challenges. A taken username is retried
+// with a suffix.
+func (a *authService) provisionWalletUser(ctx context.Context, wallet string) (User, error) {
 	user := User{
-		ID:                    id,
-		Username:              newUser.Username,
-		SolanaWalletPublicKey: newUser.SolanaWalletPublicKey,
-		PasswordHash:          newUser.PasswordHash,
+		ID:                    uuid.New(),
+		SolanaWalletPublicKey: wallet,
 		Games:                 []string{},
+		Roles:                 defaultRoles(),
+		CreatedAt:             createdNow(),
+	}
+	var err error
+	for attempt := 0; attempt < walletUsernameAttempts; attempt++ {
+		user.Username = walletUsername(wallet, attempt)
+		if err = a.userStorage.Save(ctx, user); !errors.Is(err, ErrUsernameTaken) {
+			break
+		}
+	}
+	if err != nil {
+		a.logger.Error("provision wallet user failed", "error", err)
+		return User{}, err
+	}
+	a.logger.Info("wallet user provisioned", "userId", user.ID)
+	return user, nil
+}
+
+// lookupRefreshToken verifies a refresh token and returns its stored record.
+func (a *authService) lookupRefreshToken(ctx context.Context, refreshToken string) (RefreshToken, error) {
+	claims, err := a.parseToken(refreshToken, TokenTypeRefresh)
+	if err != nil {
+		return RefreshToken{}, err
+	}
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return RefreshToken{}, ErrInvalidToken
+	}
+	stored, err := a.tokenStorage.FindRefreshToken(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return RefreshToken{}, ErrInvalidToken
 	}
-	err := s.userStorage.Save(ctx, user)
 	if err != nil {
+		return RefreshToken{}, err
+	}
+	if stored.RevokedAt != nil {
+		return RefreshToken{}, ErrInvalidToken
+	}
+	return stored, nil
+}
+
+// issue signs a new access token and a refresh token in familyID, and
+// persists the refresh token.
+func (a *authService) issue(ctx context.Context, user User, familyID uuid.UUID) (TokenPair, error) {
+	userID := user.ID
+	now := time.Now().UTC()
+	accessToken, err := a.makeToken(uuid.New(), userID, TokenTypeAccess, user.Roles, now, a.accessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	stored := RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(a.refreshTTL),
+	}
+	refreshToken, err := a.makeToken(stored.ID, userID, TokenTypeRefresh, nil, now, a.refreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if err := a.tokenStorage.SaveRefreshToken(ctx, stored); err != nil {
+		a.logger.Error("save refresh token failed", "error", err, "userId", userID)
+		return TokenPair{}, err
+	}
+	return TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// parseToken verifies the signature, expiry and type of a token.
+func (a *authService) parseToken(token, typ string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	tkn, err := jwt.ParseWithClaims(token, claims, a.keys.Keyfunc)
+	if err != nil || !tkn.Valid || claims.Type != typ {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (a *authService) makeToken(id, userID uuid.UUID, typ string, roles []Role, now time.Time, ttl time.Duration) (string, error) {
+	return a.keys.Sign(tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id.String(),
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Type:  typ,
+		Roles: roles,
+	})
+}
+
+// createdNow returns the current time at the microsecond precision
+// Postgres keeps, so stored users compare equal to the ones returned.
+func createdNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// Service methods (Register, FindOne, Find, List, SetRoles, Update, ChangePassword, Delete)
+
+func (s *service) Register(ctx context.Context, newUser NewUser) (uuid.UUID, error) {
+	if err := newUser.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+	// checked up front for a clear error; the unique constraints in storage
+	// still catch concurrent registrations
+	if err := s.checkAvailable(ctx, newUser); err != nil {
+		return uuid.Nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	user := User{
+		ID:           uuid.New(),
+		Username:     newUser.Username,
+		PasswordHash: string(hash),
+		Games:        []string{},
+		Roles:        defaultRoles(),
+		CreatedAt:    createdNow(),
+	}
+	if err := s.userStorage.Save(ctx, user); err != nil {
 		s.logger.Error("register failed", "error", err)
+		return uuid.Nil, err
+	}
+	s.logger.Info("user registered", "userId", user.ID)
+	return user.ID, nil
+}
+
+// checkAvailable reports ErrUsernameTaken if newUser collides with an
+// existing user.
+func (s *service) checkAvailable(ctx context.Context, newUser NewUser) error {
+	_, err := s.userStorage.FindOneByUsername(ctx, newUser.Username)
+	if err == nil {
+		return ErrUsernameTaken
 	}
-	return err
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
 }
 
 func (s *service) FindOne(ctx context.Context, filter SingleFilter) (User, error) {
@@ -145,5 +451,103 @@ func (s *service) FindOne(ctx context.Context, filter SingleFilter) (User, error
 }
 
 func (s *service) Find(ctx context.Context, filter Filter) ([]User, error) {
-	return s.userStorage.Find(ctx, filter)
+	return s.userStorage.Find(ctx, filter.normalized())
+}
+
+func (s *service) List(ctx context.Context, query ListQuery) (Page, error) {
+	sq, err := query.storageQuery()
+	if err != nil {
+		return Page{}, err
+	}
+	// fetch one extra user to learn whether another page follows
+	limit := sq.Limit
+	sq.Limit++
+	users, total, err := s.userStorage.List(ctx, sq)
+	if err != nil {
+		return Page{}, err
+	}
+	page := Page{Users: users, Total: total}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		last := page.Users[limit-1]
+		page.NextCursor = EncodeCursor(Cursor{Sort: sq.Sort, Desc: sq.Desc, Value: SortValue(last, sq.Sort), ID: last.ID})
+	}
+	return page, nil
+}
+
+func (s *service) SetRoles(ctx context.Context, id uuid.UUID, roles []Role) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("%w: at least one role is required", ErrInvalidRole)
+	}
+	for _, r := range roles {
+		if !r.Valid() {
+			return fmt.Errorf("%w: %q", ErrInvalidRole, r)
+		}
+	}
+	user, err := s.userStorage.FindOneByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	user.Roles = roles
+	if err := s.userStorage.Save(ctx, user); err != nil {
+		s.logger.Error("set roles failed", "error", err, "userId", id)
+		return err
+	}
+	s.logger.Info("user roles changed", "userId", id, "roles", roles)
+	return nil
+}
+
+func (s *service) Update(ctx context.Context, id uuid.UUID, update UserUpdate) (User, error) {
+	user, err := s.userStorage.FindOneByID(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+	user, err = update.apply(user)
+	if err != nil {
+		return User{}, err
+	}
+	// the unique constraints in storage report taken usernames and wallets
+	if err := s.userStorage.Save(ctx, user); err != nil {
+		s.logger.Error("update user failed", "error", err, "userId", id)
+		return User{}, err
+	}
+	s.logger.Info("user updated", "userId", id)
+	return user, nil
+}
+
+func (s *service) ChangePassword(ctx context.Context, id uuid.UUID, current, next string) error {
+	if err := validatePassword(next); err != nil {
+		return err
+	}
+	user, err := s.userStorage.FindOneByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash != "" || current != "" {
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)) != nil {
+			return ErrInvalidCredentials
+		}
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hash)
+	if err := s.userStorage.Save(ctx, user); err != nil {
+		s.logger.Error("change password failed", "error", err, "userId", id)
+		return err
+	}
+	s.logger.Info("user password changed", "userId", id)
+	return nil
+}
+
+func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := s.userStorage.Delete(ctx, id); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			s.logger.Error("delete user failed", "error", err, "userId", id)
+		}
+		return err
+	}
+	s.logger.Info("user deleted", "userId", id)
+	return nil
 }
@@ -3,15 +3,50 @@ package user
 import (
 	"context"
 	"github.com/google/uuid"
+	"time"
 )
 
+// Storage persists users. Lookups of missing users return ErrNotFound and
+// writes violating a unique constraint return ErrUsernameTaken or
+// ErrWalletTaken, both of which match ErrConflict.
 type Storage interface {
 	Save(ctx context.Context, user User) error
 
+	// FindOne returns ErrInvalidFilter if filter sets no field.
 	FindOne(ctx context.Context, filter SingleFilter) (User, error)
 	FindOneByUsername(ctx context.Context, username string) (User, error)
 	FindOneByID(ctx context.Context, id uuid.UUID) (User, error)
 	FindOneBySolanaWallet(ctx context.Context, solanaWallet string) (User, error)
 
+	// Find returns the users matching filter, or none if filter is empty.
 	Find(ctx context.Context, filter Filter) ([]User, error)
+	// List returns up to query.Limit users matching query.Filter, ordered by
+	// query.Sort then ID and starting after query.After, together with the
+	// number of users matching the filter.
+	List(ctx context.Context, query StorageQuery) ([]User, int, error)
+
+	// Delete removes the user together with everything referencing them,
+	// as the foreign keys of the schema cascade, or returns ErrNotFound.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// TokenStorage persists refresh tokens for rotation and revocation.
+type TokenStorage interface {
+	SaveRefreshToken(ctx context.Context, token RefreshToken) error
+	// FindRefreshToken returns ErrNotFound if the token was never stored.
+	FindRefreshToken(ctx context.Context, id uuid.UUID) (RefreshToken, error)
+	// MarkRefreshTokenUsed atomically sets UsedAt on an unused, unrevoked
+	// token and reports whether it did, so concurrent refreshes with the
+	// same token cannot both succeed.
+	MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
+
+// ChallengeStorage persists wallet login challenges.
+type ChallengeStorage interface {
+	SaveChallenge(ctx context.Context, challenge WalletChallenge) error
+	// ConsumeChallenge atomically deletes an unexpired challenge and returns
+	// it, or ErrNotFound if there is none, so each nonce is usable once.
+	ConsumeChallenge(ctx context.Context, nonce string, now time.Time) (WalletChallenge, error)
 }
@@ -0,0 +1,448 @@
+// Package usertest checks that user.Storage implementations behave alike.
+package usertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestStorage runs the user.Storage conformance suite, one subtest per
+// case. newStorage is called once per case and must return an empty
+// storage; a Postgres-backed factory truncates the users table.
+func TestStorage(t *testing.T, newStorage func(t *testing.T) user.Storage) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.run(t.Context(), newStorage(t)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+type testCase struct {
+	name string
+	run  func(ctx context.Context, s user.Storage) error
+}
+
+var cases = []testCase{
+	{"save and find by id", testSaveFind},
+	{"save updates by id", testSaveUpdates},
+	{"unique username", testUniqueUsername},
+	{"unique wallet", testUniqueWallet},
+	{"find one by key", testFindOne},
+	{"find one missing", testFindOneMissing},
+	{"find combines criteria", testFindCombines},
+	{"results are copies", testCopies},
+	{"concurrent saves", testConcurrentSaves},
+	{"find by username and game", testFindSearch},
+	{"list pages", testListPages},
+	{"list filters and totals", testListFilters},
+	{"delete", testDelete},
+}
+
+func newUser(username, wallet string) user.User {
+	return user.User{
+		ID:                    uuid.New(),
+		Username:              username,
+		SolanaWalletPublicKey: wallet,
+		PasswordHash:          "hash-" + username,
+		Games:                 []string{"valorant", "cs2"},
+		Roles:                 []user.Role{user.RolePlayer},
+		CreatedAt:             time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func equalUser(got, want user.User) error {
+	if got.ID != want.ID || got.Username != want.Username || !got.CreatedAt.Equal(want.CreatedAt) ||
+		got.SolanaWalletPublicKey != want.SolanaWalletPublicKey || got.PasswordHash != want.PasswordHash {
+		return fmt.Errorf("got user %+v, want %+v", got, want)
+	}
+	if fmt.Sprint(got.Games) != fmt.Sprint(want.Games) || fmt.Sprint(got.Roles) != fmt.Sprint(want.Roles) {
+		return fmt.Errorf("got games %v roles %v, want games %v roles %v", got.Games, got.Roles, want.Games, want.Roles)
+	}
+	return nil
+}
+
+func testSaveFind(ctx context.Context, s user.Storage) error {
+	u := newUser("alice", "wallet-alice")
+	if err := s.Save(ctx, u); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	got, err := s.FindOneByID(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("find by id: %w", err)
+	}
+	return equalUser(got, u)
+}
+
+func testSaveUpdates(ctx context.Context, s user.Storage) error {
+	u := newUser("bob", "")
+	if err := s.Save(ctx, u); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	u.Username = "bobby"
+	u.Games = []string{"dota2"}
+	u.Roles = []user.Role{user.RolePlayer, user.RoleCoach}
+	if err := s.Save(ctx, u); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	got, err := s.FindOneByID(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("find by id: %w", err)
+	}
+	if err := equalUser(got, u); err != nil {
+		return err
+	}
+	if _, err := s.FindOneByUsername(ctx, "bob"); !errors.Is(err, user.ErrNotFound) {
+		return fmt.Errorf("old username: got %v, want ErrNotFound", err)
+	}
+	return nil
+}
+
+func testUniqueUsername(ctx context.Context, s user.Storage) error {
+	if err := s.Save(ctx, newUser("carol", "")); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	err := s.Save(ctx, newUser("carol", ""))
+	if !errors.Is(err, user.ErrUsernameTaken) || !errors.Is(err, user.ErrConflict) {
+		return fmt.Errorf("duplicate username: got %v, want ErrUsernameTaken matching ErrConflict", err)
+	}
+	return nil
+}
+
+func testUniqueWallet(ctx context.Context, s user.Storage) error {
+	if err := s.Save(ctx, newUser("dave", "wallet-shared")); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	err := s.Save(ctx, newUser("erin", "wallet-shared"))
+	if !errors.Is(err, user.ErrWalletTaken) || !errors.Is(err, user.ErrConflict) {
+		return fmt.Errorf("duplicate wallet: got %v, want ErrWalletTaken matching ErrConflict", err)
+	}
+	// users without a wallet never conflict with each other
+	if err := s.Save(ctx, newUser("frank", "")); err != nil {
+		return fmt.Errorf("first user without wallet: %w", err)
+	}
+	if err := s.Save(ctx, newUser("grace", "")); err != nil {
+		return fmt.Errorf("second user without wallet: %w", err)
+	}
+	return nil
+}
+
+func testFindOne(ctx context.Context, s user.Storage) error {
+	u := newUser("heidi", "wallet-heidi")
+	if err := s.Save(ctx, u); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	filters := map[string]user.SingleFilter{
+		"id":       {ID: &u.ID},
+		"username": {Username: &u.Username},
+		"wallet":   {SolanaWallet: &u.SolanaWalletPublicKey},
+	}
+	for key, filter := range filters {
+		got, err := s.FindOne(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("by %s: %w", key, err)
+		}
+		if err := equalUser(got, u); err != nil {
+			return fmt.Errorf("by %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func testFindOneMissing(ctx context.Context, s user.Storage) error {
+	id := uuid.New()
+	name := "nobody"
+	lookups := map[string]func() error{
+		"id":       func() error { _, err := s.FindOneByID(ctx, id); return err },
+		"username": func() error { _, err := s.FindOneByUsername(ctx, name); return err },
+		"wallet":   func() error { _, err := s.FindOneBySolanaWallet(ctx, "wallet-nobody"); return err },
+		"filter":   func() error { _, err := s.FindOne(ctx, user.SingleFilter{Username: &name}); return err },
+	}
+	for key, lookup := range lookups {
+		if err := lookup(); !errors.Is(err, user.ErrNotFound) {
+			return fmt.Errorf("by %s: got %v, want ErrNotFound", key, err)
+		}
+	}
+	if _, err := s.FindOne(ctx, user.SingleFilter{}); !errors.Is(err, user.ErrInvalidFilter) {
+		return fmt.Errorf("empty filter: got %v, want ErrInvalidFilter", err)
+	}
+	return nil
+}
+
+func testFindCombines(ctx context.Context, s user.Storage) error {
+	ivan, judy := newUser("ivan", "wallet-ivan"), newUser("judy", "wallet-judy")
+	for _, u := range []user.User{ivan, judy} {
+		if err := s.Save(ctx, u); err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+	}
+
+	checks := []struct {
+		name   string
+		filter user.Filter
+		want   []uuid.UUID
+	}{
+		{"empty filter", user.Filter{}, nil},
+		{"ids", user.Filter{IDs: []uuid.UUID{ivan.ID, judy.ID}}, []uuid.UUID{ivan.ID, judy.ID}},
+		{"ids and username", user.Filter{IDs: []uuid.UUID{ivan.ID, judy.ID}, Usernames: []string{"judy"}}, []uuid.UUID{judy.ID}},
+		{"disjoint criteria", user.Filter{Usernames: []string{"ivan"}, SolanaWallets: []string{"wallet-judy"}}, nil},
+		{"unknown values", user.Filter{Usernames: []string{"mallory"}}, nil},
+	}
+	for _, c := range checks {
+		got, err := s.Find(ctx, c.filter)
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+		if len(got) != len(c.want) {
+			return fmt.Errorf("%s: got %d users, want %d", c.name, len(got), len(c.want))
+		}
+		for _, u := range got {
+			found := false
+			for _, id := range c.want {
+				found = found || u.ID == id
+			}
+			if !found {
+				return fmt.Errorf("%s: unexpected user %s", c.name, u.Username)
+			}
+		}
+	}
+	return nil
+}
+
+func testCopies(ctx context.Context, s user.Storage) error {
+	u := newUser("kate", "")
+	if err := s.Save(ctx, u); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	u.Games[0] = "mutated"
+	got, err := s.FindOneByID(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("find by id: %w", err)
+	}
+	got.Games[0] = "mutated again"
+	again, err := s.FindOneByID(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("find by id: %w", err)
+	}
+	if again.Games[0] != "valorant" {
+		return fmt.Errorf("stored games changed through a caller's slice: %v", again.Games)
+	}
+	return nil
+}
+
+func testConcurrentSaves(ctx context.Context, s user.Storage) error {
+	const workers = 16
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		saved int
+		errs  []error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// every worker races for the same username
+			err := s.Save(ctx, newUser("leo", ""))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				saved++
+			case !errors.Is(err, user.ErrUsernameTaken):
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if saved != 1 {
+		return fmt.Errorf("%d concurrent saves of one username succeeded, want 1", saved)
+	}
+	return nil
+}
+
+// saveAll stores users named after names, created one minute apart in
+// the given order.
+func saveAll(ctx context.Context, s user.Storage, names ...string) ([]user.User, error) {
+	users := make([]user.User, 0, len(names))
+	for i, name := range names {
+		u := newUser(name, "")
+		u.CreatedAt = u.CreatedAt.Add(time.Duration(i) * time.Minute)
+		if err := s.Save(ctx, u); err != nil {
+			return nil, fmt.Errorf("save %s: %w", name, err)
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func usernames(users []user.User) string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	return strings.Join(names, ",")
+}
+
+func testFindSearch(ctx context.Context, s user.Storage) error {
+	users, err := saveAll(ctx, s, "Mike_1", "mikey", "nina")
+	if err != nil {
+		return err
+	}
+	nina := users[2]
+	nina.Games = []string{"dota2"}
+	if err := s.Save(ctx, nina); err != nil {
+		return fmt.Errorf("save nina: %w", err)
+	}
+
+	checks := []struct {
+		name   string
+		filter user.Filter
+		want   string
+	}{
+		{"prefix ignores case", user.Filter{UsernamePrefix: "MIK"}, "Mike_1,mikey"},
+		{"prefix escapes wildcards", user.Filter{UsernamePrefix: "mike_"}, "Mike_1"},
+		{"contains", user.Filter{UsernameContains: "KE"}, "Mike_1,mikey"},
+		{"contains escapes wildcards", user.Filter{UsernameContains: "%"}, ""},
+		{"game", user.Filter{Games: []string{"dota2", "league"}}, "nina"},
+		{"game and prefix", user.Filter{Games: []string{"valorant"}, UsernamePrefix: "n"}, ""},
+	}
+	for _, c := range checks {
+		got, err := s.Find(ctx, c.filter)
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+		if names := usernames(got); names != c.want {
+			return fmt.Errorf("%s: got [%s], want [%s]", c.name, names, c.want)
+		}
+	}
+	return nil
+}
+
+func testListPages(ctx context.Context, s user.Storage) error {
+	// created in this order, so creation order differs from name order
+	if _, err := saveAll(ctx, s, "quinn", "olga", "pete", "Zed", "rita"); err != nil {
+		return err
+	}
+
+	checks := []struct {
+		name string
+		sort user.SortKey
+		desc bool
+		want []string
+	}{
+		{"username", user.SortUsername, false, []string{"Zed,olga", "pete,quinn", "rita"}},
+		{"username desc", user.SortUsername, true, []string{"rita,quinn", "pete,olga", "Zed"}},
+		{"created", user.SortCreatedAt, false, []string{"quinn,olga", "pete,Zed", "rita"}},
+		{"created desc", user.SortCreatedAt, true, []string{"rita,Zed", "pete,olga", "quinn"}},
+	}
+	for _, c := range checks {
+		var after *user.Cursor
+		for i, want := range c.want {
+			got, total, err := s.List(ctx, user.StorageQuery{Sort: c.sort, Desc: c.desc, After: after, Limit: 2})
+			if err != nil {
+				return fmt.Errorf("%s page %d: %w", c.name, i+1, err)
+			}
+			if total != 5 {
+				return fmt.Errorf("%s page %d: got total %d, want 5", c.name, i+1, total)
+			}
+			if names := usernames(got); names != want {
+				return fmt.Errorf("%s page %d: got [%s], want [%s]", c.name, i+1, names, want)
+			}
+			last := got[len(got)-1]
+			after = &user.Cursor{Sort: c.sort, Desc: c.desc, Value: user.SortValue(last, c.sort), ID: last.ID}
+		}
+		got, _, err := s.List(ctx, user.StorageQuery{Sort: c.sort, Desc: c.desc, After: after, Limit: 2})
+		if err != nil {
+			return fmt.Errorf("%s past the end: %w", c.name, err)
+		}
+		if len(got) != 0 {
+			return fmt.Errorf("%s past the end: got [%s], want none", c.name, usernames(got))
+		}
+	}
+	return nil
+}
+
+func testListFilters(ctx context.Context, s user.Storage) error {
+	users, err := saveAll(ctx, s, "sam", "sara", "tom")
+	if err != nil {
+		return err
+	}
+	// equal sort values fall back to id order
+	twin := users[1]
+	twin.ID, twin.Username = uuid.New(), "sara2"
+	twin.CreatedAt = users[0].CreatedAt
+	if err := s.Save(ctx, twin); err != nil {
+		return fmt.Errorf("save twin: %w", err)
+	}
+
+	got, total, err := s.List(ctx, user.StorageQuery{Filter: user.Filter{UsernamePrefix: "sa"}, Sort: user.SortUsername, Limit: 2})
+	if err != nil {
+		return fmt.Errorf("prefix: %w", err)
+	}
+	if total != 3 || usernames(got) != "sam,sara" {
+		return fmt.Errorf("prefix: got [%s] of %d, want [sam,sara] of 3", usernames(got), total)
+	}
+
+	got, _, err = s.List(ctx, user.StorageQuery{Sort: user.SortCreatedAt, Limit: 10})
+	if err != nil {
+		return fmt.Errorf("ties: %w", err)
+	}
+	first, second := users[0], twin
+	if bytesLess(second.ID, first.ID) {
+		first, second = second, first
+	}
+	if want := first.Username + "," + second.Username + ",sara,tom"; usernames(got) != want {
+		return fmt.Errorf("ties: got [%s], want [%s]", usernames(got), want)
+	}
+
+	got, total, err = s.List(ctx, user.StorageQuery{Filter: user.Filter{Usernames: []string{"nobody"}}, Sort: user.SortUsername, Limit: 10})
+	if err != nil {
+		return fmt.Errorf("no match: %w", err)
+	}
+	if total != 0 || len(got) != 0 {
+		return fmt.Errorf("no match: got %d users of %d, want none", len(got), total)
+	}
+	return nil
+}
+
+func bytesLess(a, b uuid.UUID) bool {
+	return strings.Compare(string(a[:]), string(b[:])) < 0
+}
+
+func testDelete(ctx context.Context, s user.Storage) error {
+	kept, gone := newUser("kate", "wallet-kate"), newUser("leo", "wallet-leo")
+	for _, u := range []user.User{kept, gone} {
+		if err := s.Save(ctx, u); err != nil {
+			return fmt.Errorf("save %s: %w", u.Username, err)
+		}
+	}
+	if err := s.Delete(ctx, gone.ID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	if _, err := s.FindOneByID(ctx, gone.ID); !errors.Is(err, user.ErrNotFound) {
+		return fmt.Errorf("find deleted: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.FindOneByID(ctx, kept.ID); err != nil {
+		return fmt.Errorf("find kept: %w", err)
+	}
+	if err := s.Delete(ctx, gone.ID); !errors.Is(err, user.ErrNotFound) {
+		return fmt.Errorf("delete twice: got %v, want ErrNotFound", err)
+	}
+	// the username and wallet are free again
+	if err := s.Save(ctx, newUser(gone.Username, gone.SolanaWalletPublicKey)); err != nil {
+		return fmt.Errorf("reuse username and wallet: %w", err)
+	}
+	return nil
+}
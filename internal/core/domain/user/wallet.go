@@ -0,0 +1,74 @@
+package user
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/compiai/engine/pkg/base58"
+	"time"
+)
+
+const (
+	challengeTTL = 5 * time.Minute
+	// walletUsernamePrefix marks users provisioned by a wallet login; it is
+	// reserved so nobody can register the name of a wallet's first login.
+	walletUsernamePrefix = "sol_"
+	// walletUsernameAttempts bounds the suffixed names tried when the
+	// derived username is taken.
+	walletUsernameAttempts = 10
+)
+
+// decodeWallet parses a base58 Solana public key.
+func decodeWallet(wallet string) (ed25519.PublicKey, error) {
+	raw, err := base58.Decode(wallet)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, ErrInvalidWallet
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// newWalletChallenge builds the sign-in message for wallet.
+func newWalletChallenge(wallet string, now time.Time) (WalletChallenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return WalletChallenge{}, err
+	}
+	c := WalletChallenge{
+		Nonce:     hex.EncodeToString(nonce),
+		Wallet:    wallet,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(challengeTTL),
+	}
+	c.Message = fmt.Sprintf(
+		"Compi wants you to sign in with your Solana account:\n%s\n\nNonce: %s\nIssued At: %s\nExpiration Time: %s",
+		c.Wallet, c.Nonce, c.IssuedAt.Format(time.RFC3339), c.ExpiresAt.Format(time.RFC3339),
+	)
+	return c, nil
+}
+
+// verifyWalletSignature checks a base58 ed25519 signature of message.
+func verifyWalletSignature(pub ed25519.PublicKey, message, signature string) error {
+	sig, err := base58.Decode(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	if !ed25519.Verify(pub, []byte(message), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// walletUsername derives the username of a wallet-provisioned user from a
+// hash of the full key, so wallets sharing a prefix get different names.
+// attempt > 0 appends a suffix for retries after a collision; the result
+// stays within the 32 character username limit.
+func walletUsername(wallet string, attempt int) string {
+	sum := sha256.Sum256([]byte(wallet))
+	name := walletUsernamePrefix + hex.EncodeToString(sum[:])[:24]
+	if attempt > 0 {
+		name += fmt.Sprintf("-%d", attempt)
+	}
+	return name
+}
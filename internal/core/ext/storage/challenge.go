@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"time"
+)
+
+// PostgresChallengeStorage implements user.ChallengeStorage using a PostgreSQL database.
+type PostgresChallengeStorage struct {
+	db *sql.DB
+}
+
+// NewPostgresChallengeStorage creates a new PostgresChallengeStorage.
+func NewPostgresChallengeStorage(db *sql.DB) *PostgresChallengeStorage {
+	return &PostgresChallengeStorage{db: db}
+}
+
+func (s *PostgresChallengeStorage) SaveChallenge(ctx context.Context, c user.WalletChallenge) error {
+	query := `
+	INSERT INTO wallet_challenges (nonce, wallet, message, issued_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := s.db.ExecContext(ctx, query, c.Nonce, c.Wallet, c.Message, c.IssuedAt, c.ExpiresAt)
+	return err
+}
+
+func (s *PostgresChallengeStorage) ConsumeChallenge(ctx context.Context, nonce string, now time.Time) (user.WalletChallenge, error) {
+	var c user.WalletChallenge
+	// expired rows are swept opportunistically on every consume
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM wallet_challenges WHERE expires_at <= $1`, now); err != nil {
+		return c, err
+	}
+	query := `
+	DELETE FROM wallet_challenges WHERE nonce = $1 AND expires_at > $2
+	RETURNING nonce, wallet, message, issued_at, expires_at
+	`
+	err := s.db.QueryRowContext(ctx, query, nonce, now).Scan(&c.Nonce, &c.Wallet, &c.Message, &c.IssuedAt, &c.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, user.ErrNotFound
+	}
+	return c, err
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/coaching"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+// PostgresCoachingStorage implements coaching.Storage using a PostgreSQL database.
+type PostgresCoachingStorage struct {
+	db *sql.DB
+}
+
+// NewPostgresCoachingStorage creates a new PostgresCoachingStorage.
+func NewPostgresCoachingStorage(db *sql.DB) *PostgresCoachingStorage {
+	return &PostgresCoachingStorage{db: db}
+}
+
+const invitationColumns = `id, coach_id, player_id, scopes, status, created_at, responded_at`
+
+func (s *PostgresCoachingStorage) SaveInvitation(ctx context.Context, inv coaching.Invitation) error {
+	query := `
+	INSERT INTO coach_invitations (` + invitationColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := s.db.ExecContext(ctx, query,
+		inv.ID, inv.CoachID, inv.PlayerID, pq.Array(scopeNames(inv.Scopes)), string(inv.Status), inv.CreatedAt, inv.RespondedAt,
+	)
+	return err
+}
+
+func (s *PostgresCoachingStorage) FindInvitation(ctx context.Context, id uuid.UUID) (coaching.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM coach_invitations WHERE id = $1`
+	inv, err := scanInvitation(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return inv, coaching.ErrInvitationNotFound
+	}
+	return inv, err
+}
+
+func (s *PostgresCoachingStorage) FindInvitations(ctx context.Context, filter coaching.InvitationFilter) ([]coaching.Invitation, error) {
+	// build dynamic WHERE clauses
+	clauses := []string{}
+	args := []interface{}{}
+	idx := 1
+	if len(filter.CoachIDs) > 0 {
+		clauses = append(clauses, fmt.Sprintf("coach_id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.CoachIDs))
+		idx++
+	}
+	if len(filter.PlayerIDs) > 0 {
+		clauses = append(clauses, fmt.Sprintf("player_id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.PlayerIDs))
+		idx++
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, st := range filter.Statuses {
+			statuses = append(statuses, string(st))
+		}
+		clauses = append(clauses, fmt.Sprintf("status = ANY($%d)", idx))
+		args = append(args, pq.Array(statuses))
+		idx++
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	query := "SELECT " + invitationColumns + " FROM coach_invitations WHERE " + strings.Join(clauses, " AND ") + " ORDER BY created_at DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	invitations := []coaching.Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, rows.Err()
+}
+
+func (s *PostgresCoachingStorage) AcceptInvitation(ctx context.Context, id uuid.UUID, at time.Time) (coaching.Grant, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return coaching.Grant{}, err
+	}
+	defer tx.Rollback()
+
+	var (
+		grant  coaching.Grant
+		scopes []string
+	)
+	err = tx.QueryRowContext(ctx, `
+	UPDATE coach_invitations SET status = $2, responded_at = $3
+	WHERE id = $1 AND status = $4
+	RETURNING coach_id, player_id, scopes
+	`, id, string(coaching.InvitationAccepted), at, string(coaching.InvitationPending),
+	).Scan(&grant.CoachID, &grant.PlayerID, pq.Array(&scopes))
+	if errors.Is(err, sql.ErrNoRows) {
+		return coaching.Grant{}, coaching.ErrInvitationNotFound
+	}
+	if err != nil {
+		return coaching.Grant{}, err
+	}
+	grant.Scopes = toScopes(scopes)
+	grant.GrantedAt = at
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO coach_grants (coach_id, player_id, scopes, granted_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (coach_id, player_id) DO UPDATE
+	SET scopes = EXCLUDED.scopes,
+	    granted_at = EXCLUDED.granted_at
+	`, grant.CoachID, grant.PlayerID, pq.Array(scopes), grant.GrantedAt)
+	if err != nil {
+		return coaching.Grant{}, err
+	}
+	return grant, tx.Commit()
+}
+
+func (s *PostgresCoachingStorage) DeclineInvitation(ctx context.Context, id uuid.UUID, at time.Time) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE coach_invitations SET status = $2, responded_at = $3 WHERE id = $1 AND status = $4`,
+		id, string(coaching.InvitationDeclined), at, string(coaching.InvitationPending),
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return coaching.ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (s *PostgresCoachingStorage) FindGrant(ctx context.Context, coachID, playerID uuid.UUID) (coaching.Grant, error) {
+	query := `SELECT coach_id, player_id, scopes, granted_at FROM coach_grants WHERE coach_id = $1 AND player_id = $2`
+	grant, err := scanGrant(s.db.QueryRowContext(ctx, query, coachID, playerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return grant, coaching.ErrGrantNotFound
+	}
+	return grant, err
+}
+
+func (s *PostgresCoachingStorage) FindGrants(ctx context.Context, filter coaching.GrantFilter) ([]coaching.Grant, error) {
+	// build dynamic WHERE clauses
+	clauses := []string{}
+	args := []interface{}{}
+	idx := 1
+	if len(filter.CoachIDs) > 0 {
+		clauses = append(clauses, fmt.Sprintf("coach_id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.CoachIDs))
+		idx++
+	}
+	if len(filter.PlayerIDs) > 0 {
+		clauses = append(clauses, fmt.Sprintf("player_id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.PlayerIDs))
+		idx++
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	query := "SELECT coach_id, player_id, scopes, granted_at FROM coach_grants WHERE " + strings.Join(clauses, " AND ") + " ORDER BY granted_at"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	grants := []coaching.Grant{}
+	for rows.Next() {
+		grant, err := scanGrant(rows)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+func (s *PostgresCoachingStorage) DeleteGrant(ctx context.Context, coachID, playerID uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM coach_grants WHERE coach_id = $1 AND player_id = $2`, coachID, playerID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return coaching.ErrGrantNotFound
+	}
+	return nil
+}
+
+func scanInvitation(row rowScanner) (coaching.Invitation, error) {
+	var (
+		inv         coaching.Invitation
+		scopes      []string
+		status      string
+		respondedAt sql.NullTime
+	)
+	err := row.Scan(&inv.ID, &inv.CoachID, &inv.PlayerID, pq.Array(&scopes), &status, &inv.CreatedAt, &respondedAt)
+	inv.Scopes = toScopes(scopes)
+	inv.Status = coaching.InvitationStatus(status)
+	if respondedAt.Valid {
+		inv.RespondedAt = &respondedAt.Time
+	}
+	return inv, err
+}
+
+func scanGrant(row rowScanner) (coaching.Grant, error) {
+	var (
+		grant  coaching.Grant
+		scopes []string
+	)
+	err := row.Scan(&grant.CoachID, &grant.PlayerID, pq.Array(&scopes), &grant.GrantedAt)
+	grant.Scopes = toScopes(scopes)
+	return grant, err
+}
+
+func scopeNames(scopes []access.Scope) []string {
+	names := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		names = append(names, string(s))
+	}
+	return names
+}
+
+func toScopes(names []string) []access.Scope {
+	scopes := make([]access.Scope, 0, len(names))
+	for _, n := range names {
+		scopes = append(scopes, access.Scope(n))
+	}
+	return scopes
+}
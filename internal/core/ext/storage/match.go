@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+const matchColumns = `id, user_id, external_id, game, played_at, duration_seconds, role, character, map,
+	kills, deaths, assists, damage, objectives, result, details`
+
+// PostgresMatchStorage implements match.Storage using a PostgreSQL database.
+type PostgresMatchStorage struct {
+	db *sql.DB
+}
+
+// NewPostgresMatchStorage creates a new PostgresMatchStorage.
+func NewPostgresMatchStorage(db *sql.DB) *PostgresMatchStorage {
+	return &PostgresMatchStorage{db: db}
+}
+
+func (s *PostgresMatchStorage) Save(ctx context.Context, m match.Match) error {
+	query := `
+	INSERT INTO matches (` + matchColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	ON CONFLICT (id) DO UPDATE
+	SET external_id = EXCLUDED.external_id,
+	    game = EXCLUDED.game,
+	    played_at = EXCLUDED.played_at,
+	    duration_seconds = EXCLUDED.duration_seconds,
+	    role = EXCLUDED.role,
+	    character = EXCLUDED.character,
+	    map = EXCLUDED.map,
+	    kills = EXCLUDED.kills,
+	    deaths = EXCLUDED.deaths,
+	    assists = EXCLUDED.assists,
+	    damage = EXCLUDED.damage,
+	    objectives = EXCLUDED.objectives,
+	    result = EXCLUDED.result,
+	    details = EXCLUDED.details
+	`
+	_, err := s.db.ExecContext(ctx, query,
+		m.ID, m.UserID, m.ExternalID, m.Game, m.PlayedAt, int64(m.Duration/time.Second), m.Role, m.Character, m.Map,
+		m.Kills, m.Deaths, m.Assists, m.Damage, m.Objectives, string(m.Result), nullableJSON(m.Details),
+	)
+	return err
+}
+
+func (s *PostgresMatchStorage) Insert(ctx context.Context, matches []match.Match) ([]match.Match, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO matches (`+matchColumns+`)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	ON CONFLICT (user_id, external_id) DO NOTHING
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	inserted := []match.Match{}
+	for _, m := range matches {
+		res, err := stmt.ExecContext(ctx,
+			m.ID, m.UserID, m.ExternalID, m.Game, m.PlayedAt, int64(m.Duration/time.Second), m.Role, m.Character, m.Map,
+			m.Kills, m.Deaths, m.Assists, m.Damage, m.Objectives, string(m.Result), nullableJSON(m.Details),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n > 0 {
+			inserted = append(inserted, m)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+func (s *PostgresMatchStorage) FindOneByID(ctx context.Context, id uuid.UUID) (match.Match, error) {
+	query := `SELECT ` + matchColumns + ` FROM matches WHERE id = $1`
+	return scanMatch(s.db.QueryRowContext(ctx, query, id))
+}
+
+func (s *PostgresMatchStorage) Find(ctx context.Context, filter match.Filter) ([]match.Match, error) {
+	// build dynamic WHERE clauses
+	clauses := []string{}
+	args := []interface{}{}
+	idx := 1
+	if len(filter.UserIDs) > 0 {
+		clauses = append(clauses, fmt.Sprintf("user_id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.UserIDs))
+		idx++
+	}
+	if len(filter.Games) > 0 {
+		clauses = append(clauses, fmt.Sprintf("game = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.Games))
+		idx++
+	}
+	if filter.From != nil {
+		clauses = append(clauses, fmt.Sprintf("played_at >= $%d", idx))
+		args = append(args, *filter.From)
+		idx++
+	}
+	if filter.To != nil {
+		clauses = append(clauses, fmt.Sprintf("played_at < $%d", idx))
+		args = append(args, *filter.To)
+		idx++
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	query := "SELECT " + matchColumns + " FROM matches WHERE " + strings.Join(clauses, " AND ") + " ORDER BY played_at DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", idx)
+		args = append(args, filter.Limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	matches := []match.Match{}
+	for rows.Next() {
+		m, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMatch(row rowScanner) (match.Match, error) {
+	var (
+		m               match.Match
+		durationSeconds int64
+		result          string
+		details         []byte
+	)
+	err := row.Scan(
+		&m.ID, &m.UserID, &m.ExternalID, &m.Game, &m.PlayedAt, &durationSeconds, &m.Role, &m.Character, &m.Map,
+		&m.Kills, &m.Deaths, &m.Assists, &m.Damage, &m.Objectives, &result, &details,
+	)
+	m.Duration = time.Duration(durationSeconds) * time.Second
+	m.Result = match.Result(result)
+	if len(details) > 0 {
+		m.Details = json.RawMessage(details)
+	}
+	return m, err
+}
+
+// nullableJSON maps an empty payload to SQL NULL.
+func nullableJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
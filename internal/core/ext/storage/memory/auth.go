@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// TokenStorage implements user.TokenStorage in memory.
+type TokenStorage struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]user.RefreshToken
+}
+
+// NewTokenStorage creates an empty TokenStorage.
+func NewTokenStorage() *TokenStorage {
+	return &TokenStorage{tokens: map[uuid.UUID]user.RefreshToken{}}
+}
+
+func (s *TokenStorage) SaveRefreshToken(ctx context.Context, t user.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[t.ID] = t
+	return nil
+}
+
+func (s *TokenStorage) FindRefreshToken(ctx context.Context, id uuid.UUID) (user.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[id]
+	if !ok {
+		return user.RefreshToken{}, user.ErrNotFound
+	}
+	return t, nil
+}
+
+func (s *TokenStorage) MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[id]
+	if !ok || t.UsedAt != nil || t.RevokedAt != nil {
+		return false, nil
+	}
+	t.UsedAt = &at
+	s.tokens[id] = t
+	return true, nil
+}
+
+func (s *TokenStorage) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	s.revoke(at, func(t user.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (s *TokenStorage) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	s.revoke(at, func(t user.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+func (s *TokenStorage) revoke(at time.Time, match func(user.RefreshToken) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, t := range s.tokens {
+		if t.RevokedAt == nil && match(t) {
+			t.RevokedAt = &at
+			s.tokens[id] = t
+		}
+	}
+}
+
+func (s *TokenStorage) deleteUser(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, t := range s.tokens {
+		if t.UserID == id {
+			delete(s.tokens, jti)
+		}
+	}
+}
+
+// ChallengeStorage implements user.ChallengeStorage in memory.
+type ChallengeStorage struct {
+	mu         sync.Mutex
+	challenges map[string]user.WalletChallenge
+}
+
+// NewChallengeStorage creates an empty ChallengeStorage.
+func NewChallengeStorage() *ChallengeStorage {
+	return &ChallengeStorage{challenges: map[string]user.WalletChallenge{}}
+}
+
+func (s *ChallengeStorage) SaveChallenge(ctx context.Context, c user.WalletChallenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.challenges[c.Nonce] = c
+	return nil
+}
+
+func (s *ChallengeStorage) ConsumeChallenge(ctx context.Context, nonce string, now time.Time) (user.WalletChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// expired challenges are swept opportunistically on every consume
+	for n, c := range s.challenges {
+		if !c.ExpiresAt.After(now) {
+			delete(s.challenges, n)
+		}
+	}
+	c, ok := s.challenges[nonce]
+	if !ok {
+		return user.WalletChallenge{}, user.ErrNotFound
+	}
+	delete(s.challenges, nonce)
+	return c, nil
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"github.com/compiai/engine/internal/core/domain/access"
+	"github.com/compiai/engine/internal/core/domain/coaching"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CoachingStorage implements coaching.Storage in memory.
+type CoachingStorage struct {
+	mu          sync.Mutex
+	invitations map[uuid.UUID]coaching.Invitation
+	grants      map[grantKey]coaching.Grant
+}
+
+type grantKey struct {
+	coachID, playerID uuid.UUID
+}
+
+// NewCoachingStorage creates an empty CoachingStorage.
+func NewCoachingStorage() *CoachingStorage {
+	return &CoachingStorage{invitations: map[uuid.UUID]coaching.Invitation{}, grants: map[grantKey]coaching.Grant{}}
+}
+
+func (s *CoachingStorage) SaveInvitation(ctx context.Context, inv coaching.Invitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv.Scopes = cloneScopes(inv.Scopes)
+	s.invitations[inv.ID] = inv
+	return nil
+}
+
+func (s *CoachingStorage) FindInvitation(ctx context.Context, id uuid.UUID) (coaching.Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv, ok := s.invitations[id]
+	if !ok {
+		return coaching.Invitation{}, coaching.ErrInvitationNotFound
+	}
+	inv.Scopes = cloneScopes(inv.Scopes)
+	return inv, nil
+}
+
+func (s *CoachingStorage) FindInvitations(ctx context.Context, filter coaching.InvitationFilter) ([]coaching.Invitation, error) {
+	if len(filter.CoachIDs) == 0 && len(filter.PlayerIDs) == 0 && len(filter.Statuses) == 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invitations := []coaching.Invitation{}
+	for _, inv := range s.invitations {
+		if len(filter.CoachIDs) > 0 && !contains(filter.CoachIDs, inv.CoachID) {
+			continue
+		}
+		if len(filter.PlayerIDs) > 0 && !contains(filter.PlayerIDs, inv.PlayerID) {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !contains(filter.Statuses, inv.Status) {
+			continue
+		}
+		inv.Scopes = cloneScopes(inv.Scopes)
+		invitations = append(invitations, inv)
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].CreatedAt.After(invitations[j].CreatedAt) })
+	return invitations, nil
+}
+
+func (s *CoachingStorage) AcceptInvitation(ctx context.Context, id uuid.UUID, at time.Time) (coaching.Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv, ok := s.invitations[id]
+	if !ok || inv.Status != coaching.InvitationPending {
+		return coaching.Grant{}, coaching.ErrInvitationNotFound
+	}
+	inv.Status = coaching.InvitationAccepted
+	inv.RespondedAt = &at
+	s.invitations[id] = inv
+
+	grant := coaching.Grant{CoachID: inv.CoachID, PlayerID: inv.PlayerID, Scopes: cloneScopes(inv.Scopes), GrantedAt: at}
+	s.grants[grantKey{grant.CoachID, grant.PlayerID}] = grant
+	grant.Scopes = cloneScopes(grant.Scopes)
+	return grant, nil
+}
+
+func (s *CoachingStorage) DeclineInvitation(ctx context.Context, id uuid.UUID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv, ok := s.invitations[id]
+	if !ok || inv.Status != coaching.InvitationPending {
+		return coaching.ErrInvitationNotFound
+	}
+	inv.Status = coaching.InvitationDeclined
+	inv.RespondedAt = &at
+	s.invitations[id] = inv
+	return nil
+}
+
+func (s *CoachingStorage) FindGrant(ctx context.Context, coachID, playerID uuid.UUID) (coaching.Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	grant, ok := s.grants[grantKey{coachID, playerID}]
+	if !ok {
+		return coaching.Grant{}, coaching.ErrGrantNotFound
+	}
+	grant.Scopes = cloneScopes(grant.Scopes)
+	return grant, nil
+}
+
+func (s *CoachingStorage) FindGrants(ctx context.Context, filter coaching.GrantFilter) ([]coaching.Grant, error) {
+	if len(filter.CoachIDs) == 0 && len(filter.PlayerIDs) == 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	grants := []coaching.Grant{}
+	for _, grant := range s.grants {
+		if len(filter.CoachIDs) > 0 && !contains(filter.CoachIDs, grant.CoachID) {
+			continue
+		}
+		if len(filter.PlayerIDs) > 0 && !contains(filter.PlayerIDs, grant.PlayerID) {
+			continue
+		}
+		grant.Scopes = cloneScopes(grant.Scopes)
+		grants = append(grants, grant)
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].GrantedAt.Before(grants[j].GrantedAt) })
+	return grants, nil
+}
+
+func (s *CoachingStorage) DeleteGrant(ctx context.Context, coachID, playerID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := grantKey{coachID, playerID}
+	if _, ok := s.grants[k]; !ok {
+		return coaching.ErrGrantNotFound
+	}
+	delete(s.grants, k)
+	return nil
+}
+
+func (s *CoachingStorage) deleteUser(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for invID, inv := range s.invitations {
+		if inv.CoachID == id || inv.PlayerID == id {
+			delete(s.invitations, invID)
+		}
+	}
+	for k, grant := range s.grants {
+		if grant.CoachID == id || grant.PlayerID == id {
+			delete(s.grants, k)
+		}
+	}
+}
+
+func cloneScopes(scopes []access.Scope) []access.Scope {
+	return append([]access.Scope{}, scopes...)
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"github.com/compiai/engine/internal/core/domain/match"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+)
+
+// MatchStorage implements match.Storage in memory.
+type MatchStorage struct {
+	mu      sync.RWMutex
+	matches map[uuid.UUID]match.Match
+}
+
+// NewMatchStorage creates an empty MatchStorage.
+func NewMatchStorage() *MatchStorage {
+	return &MatchStorage{matches: map[uuid.UUID]match.Match{}}
+}
+
+func (s *MatchStorage) Save(ctx context.Context, m match.Match) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matches[m.ID] = m
+	return nil
+}
+
+func (s *MatchStorage) Insert(ctx context.Context, matches []match.Match) ([]match.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type key struct {
+		userID     uuid.UUID
+		externalID string
+	}
+	stored := make(map[key]bool, len(s.matches))
+	for _, m := range s.matches {
+		stored[key{m.UserID, m.ExternalID}] = true
+	}
+	inserted := []match.Match{}
+	for _, m := range matches {
+		k := key{m.UserID, m.ExternalID}
+		if stored[k] {
+			continue
+		}
+		stored[k] = true
+		s.matches[m.ID] = m
+		inserted = append(inserted, m)
+	}
+	return inserted, nil
+}
+
+func (s *MatchStorage) FindOneByID(ctx context.Context, id uuid.UUID) (match.Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.matches[id]
+	if !ok {
+		return match.Match{}, sql.ErrNoRows
+	}
+	return m, nil
+}
+
+func (s *MatchStorage) Find(ctx context.Context, filter match.Filter) ([]match.Match, error) {
+	if len(filter.UserIDs) == 0 && len(filter.Games) == 0 && filter.From == nil && filter.To == nil {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	matches := []match.Match{}
+	for _, m := range s.matches {
+		if len(filter.UserIDs) > 0 && !contains(filter.UserIDs, m.UserID) {
+			continue
+		}
+		if len(filter.Games) > 0 && !contains(filter.Games, m.Game) {
+			continue
+		}
+		if filter.From != nil && m.PlayedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !m.PlayedAt.Before(*filter.To) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].PlayedAt.After(matches[j].PlayedAt) })
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+	return matches, nil
+}
+
+func (s *MatchStorage) deleteUser(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for matchID, m := range s.matches {
+		if m.UserID == id {
+			delete(s.matches, matchID)
+		}
+	}
+}
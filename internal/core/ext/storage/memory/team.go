@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"github.com/compiai/engine/internal/core/domain/team"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+)
+
+// TeamStorage implements team.Storage in memory.
+type TeamStorage struct {
+	mu    sync.RWMutex
+	teams map[uuid.UUID]team.Team
+}
+
+// NewTeamStorage creates an empty TeamStorage.
+func NewTeamStorage() *TeamStorage {
+	return &TeamStorage{teams: map[uuid.UUID]team.Team{}}
+}
+
+func (s *TeamStorage) Save(ctx context.Context, t team.Team) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the roster is managed with SaveMember and DeleteMember
+	roster := []team.Member{}
+	if stored, ok := s.teams[t.ID]; ok {
+		roster = stored.Roster
+		t.CreatedAt = stored.CreatedAt
+	}
+	t.Roster = roster
+	s.teams[t.ID] = t
+	return nil
+}
+
+func (s *TeamStorage) FindOneByID(ctx context.Context, id uuid.UUID) (team.Team, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.teams[id]
+	if !ok {
+		return team.Team{}, team.ErrTeamNotFound
+	}
+	return cloneTeam(t), nil
+}
+
+func (s *TeamStorage) Find(ctx context.Context, filter team.Filter) ([]team.Team, error) {
+	if len(filter.IDs) == 0 && len(filter.OwnerIDs) == 0 && len(filter.MemberIDs) == 0 {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	teams := []team.Team{}
+	for _, t := range s.teams {
+		if len(filter.IDs) > 0 && !contains(filter.IDs, t.ID) {
+			continue
+		}
+		// owners and members widen the result, as in the Postgres storage
+		if len(filter.OwnerIDs) > 0 || len(filter.MemberIDs) > 0 {
+			owned := contains(filter.OwnerIDs, t.OwnerID)
+			member := false
+			for _, id := range filter.MemberIDs {
+				member = member || t.HasMember(id)
+			}
+			if !owned && !member {
+				continue
+			}
+		}
+		teams = append(teams, cloneTeam(t))
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].CreatedAt.Before(teams[j].CreatedAt) })
+	return teams, nil
+}
+
+func (s *TeamStorage) SaveMember(ctx context.Context, teamID uuid.UUID, m team.Member) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.teams[teamID]
+	if !ok {
+		return team.ErrTeamNotFound
+	}
+	t = cloneTeam(t)
+	for i := range t.Roster {
+		if t.Roster[i].UserID == m.UserID {
+			// keep the original join date, as the Postgres upsert does
+			t.Roster[i].Role = m.Role
+			s.teams[teamID] = t
+			return nil
+		}
+	}
+	t.Roster = append(t.Roster, m)
+	s.teams[teamID] = t
+	return nil
+}
+
+func (s *TeamStorage) DeleteMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.teams[teamID]
+	if !ok {
+		return team.ErrTeamNotFound
+	}
+	roster := make([]team.Member, 0, len(t.Roster))
+	for _, m := range t.Roster {
+		if m.UserID != userID {
+			roster = append(roster, m)
+		}
+	}
+	if len(roster) == len(t.Roster) {
+		return team.ErrTeamNotFound
+	}
+	t.Roster = roster
+	s.teams[teamID] = t
+	return nil
+}
+
+// deleteUser drops the teams the user owns and takes them off every other
+// roster.
+func (s *TeamStorage) deleteUser(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for teamID, t := range s.teams {
+		if t.OwnerID == id {
+			delete(s.teams, teamID)
+			continue
+		}
+		roster := make([]team.Member, 0, len(t.Roster))
+		for _, m := range t.Roster {
+			if m.UserID != id {
+				roster = append(roster, m)
+			}
+		}
+		t.Roster = roster
+		s.teams[teamID] = t
+	}
+}
+
+func cloneTeam(t team.Team) team.Team {
+	t.Roster = append([]team.Member{}, t.Roster...)
+	return t
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"github.com/compiai/engine/internal/core/domain/quota"
+	"github.com/compiai/engine/internal/core/domain/usage"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageStorage implements usage.Storage in memory.
+type UsageStorage struct {
+	mu      sync.RWMutex
+	records map[usageKey]usage.Record
+}
+
+type usageKey struct {
+	userID          uuid.UUID
+	day             time.Time
+	provider, model string
+}
+
+// NewUsageStorage creates an empty UsageStorage.
+func NewUsageStorage() *UsageStorage {
+	return &UsageStorage{records: map[usageKey]usage.Record{}}
+}
+
+func (s *UsageStorage) Add(ctx context.Context, rec usage.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec.Day = rec.Day.UTC()
+	k := usageKey{rec.UserID, rec.Day, rec.Provider, rec.Model}
+	if stored, ok := s.records[k]; ok {
+		rec.Requests += stored.Requests
+		rec.PromptTokens += stored.PromptTokens
+		rec.CompletionTokens += stored.CompletionTokens
+		rec.CostUSD += stored.CostUSD
+	}
+	s.records[k] = rec
+	return nil
+}
+
+func (s *UsageStorage) Find(ctx context.Context, filter usage.Filter) ([]usage.Record, error) {
+	if len(filter.UserIDs) == 0 && filter.From == nil && filter.To == nil {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	records := []usage.Record{}
+	for _, r := range s.records {
+		if len(filter.UserIDs) > 0 && !contains(filter.UserIDs, r.UserID) {
+			continue
+		}
+		if filter.From != nil && r.Day.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !r.Day.Before(*filter.To) {
+			continue
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Model < b.Model
+	})
+	return records, nil
+}
+
+func (s *UsageStorage) deleteUser(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.records {
+		if k.userID == id {
+			delete(s.records, k)
+		}
+	}
+}
+
+// QuotaStorage implements quota.Storage in memory.
+type QuotaStorage struct {
+	mu        sync.Mutex
+	overrides map[uuid.UUID]quota.Override
+	counters  map[counterKey]int
+}
+
+type counterKey struct {
+	userID uuid.UUID
+	day    time.Time
+}
+
+// NewQuotaStorage creates an empty QuotaStorage.
+func NewQuotaStorage() *QuotaStorage {
+	return &QuotaStorage{overrides: map[uuid.UUID]quota.Override{}, counters: map[counterKey]int{}}
+}
+
+func (s *QuotaStorage) FindOverride(ctx context.Context, userID uuid.UUID) (*quota.Override, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.overrides[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &o, nil
+}
+
+func (s *QuotaStorage) SaveOverride(ctx context.Context, o quota.Override) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[o.UserID] = o
+	return nil
+}
+
+func (s *QuotaStorage) DeleteOverride(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overrides, userID)
+	return nil
+}
+
+func (s *QuotaStorage) ReserveAnalysis(ctx context.Context, userID uuid.UUID, day time.Time, limit int) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := counterKey{userID, day.UTC()}
+	count, ok := s.counters[k]
+	if ok && limit > 0 && count >= limit {
+		return count, false, nil
+	}
+	count++
+	s.counters[k] = count
+	return count, true, nil
+}
+
+func (s *QuotaStorage) deleteUser(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overrides, id)
+	for k := range s.counters {
+		if k.userID == id {
+			delete(s.counters, k)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package memory holds concurrency-safe in-memory implementations of the
+// domain storages for tests and local development. They mirror the
+// semantics of the PostgreSQL storages, including unique constraints, but
+// keep nothing across restarts.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UserStorage implements user.Storage in memory.
+type UserStorage struct {
+	mu      sync.RWMutex
+	users   map[uuid.UUID]user.User
+	cascade []UserReferrer
+}
+
+// UserReferrer is a storage holding data that references users. Deleting a
+// user from a UserStorage also deletes it from the storages registered with
+// CascadeTo, like the ON DELETE CASCADE foreign keys of the users table.
+type UserReferrer interface {
+	deleteUser(id uuid.UUID)
+}
+
+// NewUserStorage creates an empty UserStorage.
+func NewUserStorage() *UserStorage {
+	return &UserStorage{users: map[uuid.UUID]user.User{}}
+}
+
+func (s *UserStorage) Save(ctx context.Context, usr user.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// enforce the same unique constraints as the users table
+	for id, other := range s.users {
+		if id == usr.ID {
+			continue
+		}
+		if other.Username == usr.Username {
+			return user.ErrUsernameTaken
+		}
+		if usr.SolanaWalletPublicKey != "" && other.SolanaWalletPublicKey == usr.SolanaWalletPublicKey {
+			return user.ErrWalletTaken
+		}
+	}
+	s.users[usr.ID] = cloneUser(usr)
+	return nil
+}
+
+// CascadeTo registers storages whose data Delete removes along with the user.
+func (s *UserStorage) CascadeTo(storages ...UserReferrer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cascade = append(s.cascade, storages...)
+}
+
+func (s *UserStorage) Delete(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[id]; !ok {
+		return user.ErrNotFound
+	}
+	delete(s.users, id)
+	for _, referrer := range s.cascade {
+		referrer.deleteUser(id)
+	}
+	return nil
+}
+
+func (s *UserStorage) FindOne(ctx context.Context, filter user.SingleFilter) (user.User, error) {
+	// delegate to specific methods
+	if filter.ID != nil {
+		return s.FindOneByID(ctx, *filter.ID)
+	}
+	if filter.Username != nil {
+		return s.FindOneByUsername(ctx, *filter.Username)
+	}
+	if filter.SolanaWallet != nil {
+		return s.FindOneBySolanaWallet(ctx, *filter.SolanaWallet)
+	}
+	return user.User{}, fmt.Errorf("%w: set an id, username or solana wallet", user.ErrInvalidFilter)
+}
+
+func (s *UserStorage) FindOneByUsername(ctx context.Context, username string) (user.User, error) {
+	return s.findOne(func(u user.User) bool { return u.Username == username })
+}
+
+func (s *UserStorage) FindOneByID(ctx context.Context, id uuid.UUID) (user.User, error) {
+	return s.findOne(func(u user.User) bool { return u.ID == id })
+}
+
+func (s *UserStorage) FindOneBySolanaWallet(ctx context.Context, solanaWallet string) (user.User, error) {
+	return s.findOne(func(u user.User) bool { return u.SolanaWalletPublicKey == solanaWallet })
+}
+
+func (s *UserStorage) Find(ctx context.Context, filter user.Filter) ([]user.User, error) {
+	if filter.IsEmpty() {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := s.filter(filter)
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users, nil
+}
+
+func (s *UserStorage) List(ctx context.Context, q user.StorageQuery) ([]user.User, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := s.filter(q.Filter)
+	total := len(users)
+
+	// order by the sort key, then id, as the Postgres keyset does
+	less := func(a, b user.User) bool {
+		if c := compareSortKey(a, b, q.Sort); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(a.ID[:], b.ID[:]) < 0
+	}
+	if q.Desc {
+		asc := less
+		less = func(a, b user.User) bool { return asc(b, a) }
+	}
+	sort.Slice(users, func(i, j int) bool { return less(users[i], users[j]) })
+
+	if q.After != nil {
+		after := user.User{ID: q.After.ID, Username: q.After.Value}
+		if q.Sort == user.SortCreatedAt {
+			after.CreatedAt, _ = time.Parse(time.RFC3339Nano, q.After.Value)
+		}
+		start := sort.Search(len(users), func(i int) bool { return less(after, users[i]) })
+		users = users[start:]
+	}
+	if len(users) > q.Limit {
+		users = users[:q.Limit]
+	}
+	return users, total, nil
+}
+
+// filter returns copies of the users matching f; callers hold s.mu.
+func (s *UserStorage) filter(f user.Filter) []user.User {
+	users := []user.User{}
+	for _, u := range s.users {
+		// criteria are AND-combined, values within one are alternatives
+		if len(f.IDs) > 0 && !contains(f.IDs, u.ID) {
+			continue
+		}
+		if len(f.Usernames) > 0 && !contains(f.Usernames, u.Username) {
+			continue
+		}
+		if len(f.SolanaWallets) > 0 && !contains(f.SolanaWallets, u.SolanaWalletPublicKey) {
+			continue
+		}
+		name := strings.ToLower(u.Username)
+		if f.UsernamePrefix != "" && !strings.HasPrefix(name, strings.ToLower(f.UsernamePrefix)) {
+			continue
+		}
+		if f.UsernameContains != "" && !strings.Contains(name, strings.ToLower(f.UsernameContains)) {
+			continue
+		}
+		if len(f.Games) > 0 && !overlaps(f.Games, u.Games) {
+			continue
+		}
+		users = append(users, cloneUser(u))
+	}
+	return users
+}
+
+func (s *UserStorage) findOne(match func(user.User) bool) (user.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if match(u) {
+			return cloneUser(u), nil
+		}
+	}
+	return user.User{}, user.ErrNotFound
+}
+
+// cloneUser copies the slices of u so callers cannot mutate stored state.
+func cloneUser(u user.User) user.User {
+	u.Games = append([]string{}, u.Games...)
+	u.Roles = append([]user.Role(nil), u.Roles...)
+	return u
+}
+
+// compareSortKey compares a and b by key alone.
+func compareSortKey(a, b user.User, key user.SortKey) int {
+	if key == user.SortCreatedAt {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+	return strings.Compare(a.Username, b.Username)
+}
+
+func overlaps[T comparable](a, b []T) bool {
+	for _, v := range a {
+		if contains(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/compiai/engine/internal/core/domain/user/usertest"
+)
+
+func TestUserStorage(t *testing.T) {
+	usertest.TestStorage(t, func(t *testing.T) user.Storage {
+		return NewUserStorage()
+	})
+}
@@ -0,0 +1,13 @@
+CREATE TABLE users (
+    id                       uuid PRIMARY KEY,
+    username                 text NOT NULL,
+    solana_wallet_public_key text NOT NULL DEFAULT '',
+    password_hash            text NOT NULL DEFAULT '',
+    games                    text[] NOT NULL DEFAULT '{}',
+    roles                    text[] NOT NULL DEFAULT '{}',
+    CONSTRAINT users_username_key UNIQUE (username)
+);
+
+-- users signing up with a password have no wallet; only real keys are unique
+CREATE UNIQUE INDEX users_solana_wallet_key ON users (solana_wallet_public_key)
+    WHERE solana_wallet_public_key <> '';
@@ -0,0 +1,21 @@
+CREATE TABLE matches (
+    id               uuid PRIMARY KEY,
+    user_id          uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    external_id      text NOT NULL,
+    game             text NOT NULL,
+    played_at        timestamptz NOT NULL,
+    duration_seconds bigint NOT NULL DEFAULT 0,
+    role             text NOT NULL DEFAULT '',
+    character        text NOT NULL DEFAULT '',
+    map              text NOT NULL DEFAULT '',
+    kills            integer NOT NULL DEFAULT 0,
+    deaths           integer NOT NULL DEFAULT 0,
+    assists          integer NOT NULL DEFAULT 0,
+    damage           integer NOT NULL DEFAULT 0,
+    objectives       integer NOT NULL DEFAULT 0,
+    result           text NOT NULL,
+    details          jsonb,
+    CONSTRAINT matches_user_id_external_id_key UNIQUE (user_id, external_id)
+);
+
+CREATE INDEX matches_user_id_played_at_idx ON matches (user_id, played_at);
@@ -0,0 +1,3 @@
+DROP TABLE analysis_counters;
+DROP TABLE quota_overrides;
+DROP TABLE llm_usage;
@@ -0,0 +1,26 @@
+CREATE TABLE llm_usage (
+    user_id           uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day               date NOT NULL,
+    provider          text NOT NULL,
+    model             text NOT NULL,
+    requests          bigint NOT NULL DEFAULT 0,
+    prompt_tokens     bigint NOT NULL DEFAULT 0,
+    completion_tokens bigint NOT NULL DEFAULT 0,
+    cost_usd          double precision NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day, provider, model)
+);
+
+CREATE TABLE quota_overrides (
+    user_id        uuid PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    daily_analyses integer,
+    monthly_tokens bigint,
+    reason         text NOT NULL DEFAULT '',
+    updated_at     timestamptz NOT NULL
+);
+
+CREATE TABLE analysis_counters (
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day     date NOT NULL,
+    count   integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day)
+);
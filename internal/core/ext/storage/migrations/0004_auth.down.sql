@@ -0,0 +1,2 @@
+DROP TABLE wallet_challenges;
+DROP TABLE refresh_tokens;
@@ -0,0 +1,2 @@
+DROP TABLE coach_grants;
+DROP TABLE coach_invitations;
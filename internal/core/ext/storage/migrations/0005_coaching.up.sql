@@ -0,0 +1,21 @@
+CREATE TABLE coach_invitations (
+    id           uuid PRIMARY KEY,
+    coach_id     uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    player_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    scopes       text[] NOT NULL DEFAULT '{}',
+    status       text NOT NULL,
+    created_at   timestamptz NOT NULL,
+    responded_at timestamptz
+);
+
+CREATE INDEX coach_invitations_player_id_idx ON coach_invitations (player_id, status);
+
+CREATE TABLE coach_grants (
+    coach_id   uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    player_id  uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    scopes     text[] NOT NULL DEFAULT '{}',
+    granted_at timestamptz NOT NULL,
+    PRIMARY KEY (coach_id, player_id)
+);
+
+CREATE INDEX coach_grants_player_id_idx ON coach_grants (player_id);
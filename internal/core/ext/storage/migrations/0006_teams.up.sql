@@ -0,0 +1,19 @@
+CREATE TABLE teams (
+    id         uuid PRIMARY KEY,
+    name       text NOT NULL,
+    game       text NOT NULL,
+    owner_id   uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX teams_owner_id_idx ON teams (owner_id);
+
+CREATE TABLE team_members (
+    team_id   uuid NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id   uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role      text NOT NULL,
+    joined_at timestamptz NOT NULL,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX team_members_user_id_idx ON team_members (user_id);
@@ -0,0 +1,5 @@
+DROP INDEX users_games_idx;
+DROP INDEX users_created_at_id_idx;
+DROP INDEX users_username_c_id_idx;
+
+ALTER TABLE users DROP COLUMN created_at;
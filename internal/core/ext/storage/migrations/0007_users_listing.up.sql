@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN created_at timestamptz NOT NULL DEFAULT now();
+
+-- keyset pagination orders by (sort key, id); usernames sort bytewise
+CREATE INDEX users_username_c_id_idx ON users ((username COLLATE "C"), id);
+CREATE INDEX users_created_at_id_idx ON users (created_at, id);
+CREATE INDEX users_games_idx ON users USING gin (games);
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/compiai/engine/internal/core/domain/quota"
+	"github.com/google/uuid"
+	"time"
+)
+
+// PostgresQuotaStorage implements quota.Storage using a PostgreSQL database.
+type PostgresQuotaStorage struct {
+	db *sql.DB
+}
+
+// NewPostgresQuotaStorage creates a new PostgresQuotaStorage.
+func NewPostgresQuotaStorage(db *sql.DB) *PostgresQuotaStorage {
+	return &PostgresQuotaStorage{db: db}
+}
+
+func (s *PostgresQuotaStorage) FindOverride(ctx context.Context, userID uuid.UUID) (*quota.Override, error) {
+	var (
+		o             quota.Override
+		dailyAnalyses sql.NullInt64
+		monthlyTokens sql.NullInt64
+	)
+	query := `
+	SELECT user_id, daily_analyses, monthly_tokens, reason, updated_at
+	FROM quota_overrides WHERE user_id = $1
+	`
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&o.UserID, &dailyAnalyses, &monthlyTokens, &o.Reason, &o.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dailyAnalyses.Valid {
+		v := int(dailyAnalyses.Int64)
+		o.DailyAnalyses = &v
+	}
+	if monthlyTokens.Valid {
+		v := int(monthlyTokens.Int64)
+		o.MonthlyTokens = &v
+	}
+	return &o, nil
+}
+
+func (s *PostgresQuotaStorage) SaveOverride(ctx context.Context, o quota.Override) error {
+	query := `
+	INSERT INTO quota_overrides (user_id, daily_analyses, monthly_tokens, reason, updated_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id) DO UPDATE
+	SET daily_analyses = EXCLUDED.daily_analyses,
+	    monthly_tokens = EXCLUDED.monthly_tokens,
+	    reason = EXCLUDED.reason,
+	    updated_at = EXCLUDED.updated_at
+	`
+	_, err := s.db.ExecContext(ctx, query, o.UserID, o.DailyAnalyses, o.MonthlyTokens, o.Reason, o.UpdatedAt)
+	return err
+}
+
+func (s *PostgresQuotaStorage) DeleteOverride(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM quota_overrides WHERE user_id = $1`, userID)
+	return err
+}
+
+func (s *PostgresQuotaStorage) ReserveAnalysis(ctx context.Context, userID uuid.UUID, day time.Time, limit int) (int, bool, error) {
+	// the conditional upsert makes check-and-increment a single atomic statement
+	query := `
+	INSERT INTO analysis_counters (user_id, day, count)
+	VALUES ($1, $2, 1)
+	ON CONFLICT (user_id, day) DO UPDATE
+	SET count = analysis_counters.count + 1
+	WHERE $3 <= 0 OR analysis_counters.count < $3
+	RETURNING count
+	`
+	var count int
+	err := s.db.QueryRowContext(ctx, query, userID, day, limit).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the WHERE clause rejected the update: the limit is reached
+		err = s.db.QueryRowContext(ctx,
+			`SELECT count FROM analysis_counters WHERE user_id = $1 AND day = $2`, userID, day,
+		).Scan(&count)
+		return count, false, err
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
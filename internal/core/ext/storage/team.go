@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/team"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// PostgresTeamStorage implements team.Storage using a PostgreSQL database.
+type PostgresTeamStorage struct {
+	db *sql.DB
+}
+
+// NewPostgresTeamStorage creates a new PostgresTeamStorage.
+func NewPostgresTeamStorage(db *sql.DB) *PostgresTeamStorage {
+	return &PostgresTeamStorage{db: db}
+}
+
+const teamColumns = `id, name, game, owner_id, created_at`
+
+func (s *PostgresTeamStorage) Save(ctx context.Context, t team.Team) error {
+	query := `
+	INSERT INTO teams (` + teamColumns + `)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (id) DO UPDATE
+	SET name = EXCLUDED.name,
+	    game = EXCLUDED.game,
+	    owner_id = EXCLUDED.owner_id
+	`
+	_, err := s.db.ExecContext(ctx, query, t.ID, t.Name, t.Game, t.OwnerID, t.CreatedAt)
+	return err
+}
+
+func (s *PostgresTeamStorage) FindOneByID(ctx context.Context, id uuid.UUID) (team.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1`
+	t, err := scanTeam(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, team.ErrTeamNotFound
+	}
+	if err != nil {
+		return t, err
+	}
+	teams := []team.Team{t}
+	if err := s.loadRosters(ctx, teams); err != nil {
+		return team.Team{}, err
+	}
+	return teams[0], nil
+}
+
+func (s *PostgresTeamStorage) Find(ctx context.Context, filter team.Filter) ([]team.Team, error) {
+	// build dynamic WHERE clauses
+	clauses := []string{}
+	args := []interface{}{}
+	idx := 1
+	if len(filter.IDs) > 0 {
+		clauses = append(clauses, fmt.Sprintf("id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.IDs))
+		idx++
+	}
+	// owners and members widen the result: a caller sees the teams they run
+	// as well as the teams they play on
+	var scopes []string
+	if len(filter.OwnerIDs) > 0 {
+		scopes = append(scopes, fmt.Sprintf("owner_id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.OwnerIDs))
+		idx++
+	}
+	if len(filter.MemberIDs) > 0 {
+		scopes = append(scopes, fmt.Sprintf("id IN (SELECT team_id FROM team_members WHERE user_id = ANY($%d))", idx))
+		args = append(args, pq.Array(filter.MemberIDs))
+		idx++
+	}
+	if len(scopes) > 0 {
+		clauses = append(clauses, "("+strings.Join(scopes, " OR ")+")")
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	query := "SELECT " + teamColumns + " FROM teams WHERE " + strings.Join(clauses, " AND ") + " ORDER BY created_at"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	teams := []team.Team{}
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return teams, s.loadRosters(ctx, teams)
+}
+
+func (s *PostgresTeamStorage) SaveMember(ctx context.Context, teamID uuid.UUID, m team.Member) error {
+	query := `
+	INSERT INTO team_members (team_id, user_id, role, joined_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (team_id, user_id) DO UPDATE
+	SET role = EXCLUDED.role
+	`
+	_, err := s.db.ExecContext(ctx, query, teamID, m.UserID, m.Role, m.JoinedAt)
+	return err
+}
+
+func (s *PostgresTeamStorage) DeleteMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return team.ErrTeamNotFound
+	}
+	return nil
+}
+
+// loadRosters fills in the roster of every team in place with one query.
+func (s *PostgresTeamStorage) loadRosters(ctx context.Context, teams []team.Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(teams))
+	byID := make(map[uuid.UUID]*team.Team, len(teams))
+	for i := range teams {
+		teams[i].Roster = []team.Member{}
+		ids = append(ids, teams[i].ID)
+		byID[teams[i].ID] = &teams[i]
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT team_id, user_id, role, joined_at FROM team_members WHERE team_id = ANY($1) ORDER BY joined_at`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			teamID uuid.UUID
+			m      team.Member
+		)
+		if err := rows.Scan(&teamID, &m.UserID, &m.Role, &m.JoinedAt); err != nil {
+			return err
+		}
+		if t, ok := byID[teamID]; ok {
+			t.Roster = append(t.Roster, m)
+		}
+	}
+	return rows.Err()
+}
+
+func scanTeam(row rowScanner) (team.Team, error) {
+	var t team.Team
+	err := row.Scan(&t.ID, &t.Name, &t.Game, &t.OwnerID, &t.CreatedAt)
+	return t, err
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/google/uuid"
+	"time"
+)
+
+// PostgresTokenStorage implements user.TokenStorage using a PostgreSQL database.
+type PostgresTokenStorage struct {
+	db *sql.DB
+}
+
+// NewPostgresTokenStorage creates a new PostgresTokenStorage.
+func NewPostgresTokenStorage(db *sql.DB) *PostgresTokenStorage {
+	return &PostgresTokenStorage{db: db}
+}
+
+func (s *PostgresTokenStorage) SaveRefreshToken(ctx context.Context, t user.RefreshToken) error {
+	query := `
+	INSERT INTO refresh_tokens (id, user_id, family_id, issued_at, expires_at, used_at, revoked_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := s.db.ExecContext(ctx, query, t.ID, t.UserID, t.FamilyID, t.IssuedAt, t.ExpiresAt, t.UsedAt, t.RevokedAt)
+	return err
+}
+
+func (s *PostgresTokenStorage) FindRefreshToken(ctx context.Context, id uuid.UUID) (user.RefreshToken, error) {
+	var (
+		t         user.RefreshToken
+		usedAt    sql.NullTime
+		revokedAt sql.NullTime
+	)
+	query := `
+	SELECT id, user_id, family_id, issued_at, expires_at, used_at, revoked_at
+	FROM refresh_tokens WHERE id = $1
+	`
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.IssuedAt, &t.ExpiresAt, &usedAt, &revokedAt,
+	)
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, user.ErrNotFound
+	}
+	return t, err
+}
+
+func (s *PostgresTokenStorage) MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+		id, at,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *PostgresTokenStorage) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID, at,
+	)
+	return err
+}
+
+func (s *PostgresTokenStorage) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID, at,
+	)
+	return err
+}
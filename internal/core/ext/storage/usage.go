@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/compiai/engine/internal/core/domain/usage"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// PostgresUsageStorage implements usage.Storage using a PostgreSQL database.
+type PostgresUsageStorage struct {
+	db *sql.DB
+}
+
+// NewPostgresUsageStorage creates a new PostgresUsageStorage.
+func NewPostgresUsageStorage(db *sql.DB) *PostgresUsageStorage {
+	return &PostgresUsageStorage{db: db}
+}
+
+func (s *PostgresUsageStorage) Add(ctx context.Context, rec usage.Record) error {
+	query := `
+	INSERT INTO llm_usage (user_id, day, provider, model, requests, prompt_tokens, completion_tokens, cost_usd)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (user_id, day, provider, model) DO UPDATE
+	SET requests = llm_usage.requests + EXCLUDED.requests,
+	    prompt_tokens = llm_usage.prompt_tokens + EXCLUDED.prompt_tokens,
+	    completion_tokens = llm_usage.completion_tokens + EXCLUDED.completion_tokens,
+	    cost_usd = llm_usage.cost_usd + EXCLUDED.cost_usd
+	`
+	_, err := s.db.ExecContext(ctx, query,
+		rec.UserID, rec.Day, rec.Provider, rec.Model, rec.Requests, rec.PromptTokens, rec.CompletionTokens, rec.CostUSD,
+	)
+	return err
+}
+
+func (s *PostgresUsageStorage) Find(ctx context.Context, filter usage.Filter) ([]usage.Record, error) {
+	// build dynamic WHERE clauses
+	clauses := []string{}
+	args := []interface{}{}
+	idx := 1
+	if len(filter.UserIDs) > 0 {
+		clauses = append(clauses, fmt.Sprintf("user_id = ANY($%d)", idx))
+		args = append(args, pq.Array(filter.UserIDs))
+		idx++
+	}
+	if filter.From != nil {
+		clauses = append(clauses, fmt.Sprintf("day >= $%d", idx))
+		args = append(args, *filter.From)
+		idx++
+	}
+	if filter.To != nil {
+		clauses = append(clauses, fmt.Sprintf("day < $%d", idx))
+		args = append(args, *filter.To)
+		idx++
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	query := `SELECT user_id, day, provider, model, requests, prompt_tokens, completion_tokens, cost_usd
+	FROM llm_usage WHERE ` + strings.Join(clauses, " AND ") + " ORDER BY day, provider, model"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := []usage.Record{}
+	for rows.Next() {
+		var r usage.Record
+		err := rows.Scan(&r.UserID, &r.Day, &r.Provider, &r.Model, &r.Requests, &r.PromptTokens, &r.CompletionTokens, &r.CostUSD)
+		if err != nil {
+			return nil, err
+		}
+		r.Day = r.Day.UTC()
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
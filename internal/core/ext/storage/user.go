@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/compiai/engine/internal/core/domain/user"
 	"github.com/google/uuid"
@@ -10,6 +11,17 @@ import (
 	"strings"
 )
 
+// PostgreSQL error codes and classes mapped to domain errors.
+const (
+	uniqueViolation = "23505"
+	// integrityViolation covers foreign key, not-null and check violations.
+	integrityViolation pq.ErrorClass = "23"
+	// dataException covers malformed input such as invalid text representations.
+	dataException pq.ErrorClass = "22"
+)
+
+const userColumns = `id, username, solana_wallet_public_key, password_hash, games, roles, created_at`
+
 // PostgresStorage implements Storage using a PostgreSQL database.
 type PostgresStorage struct {
 	db *sql.DB
@@ -22,18 +34,19 @@ func NewPostgresStorage(db *sql.DB) *PostgresStorage {
 
 func (s *PostgresStorage) Save(ctx context.Context, usr user.User) error {
 	query := `
-	INSERT INTO users (id, username, solana_wallet_public_key, password_hash, games)
-	VALUES ($1, $2, $3, $4, $5)
+	INSERT INTO users (` + userColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
 	ON CONFLICT (id) DO UPDATE
 	SET username = EXCLUDED.username,
 	    solana_wallet_public_key = EXCLUDED.solana_wallet_public_key,
 	    password_hash = EXCLUDED.password_hash,
-	    games = EXCLUDED.games
+	    games = EXCLUDED.games,
+	    roles = EXCLUDED.roles
 	`
 	_, err := s.db.ExecContext(ctx, query,
-		usr.ID, usr.Username, usr.SolanaWalletPublicKey, usr.PasswordHash, pq.Array(usr.Games),
+		usr.ID, usr.Username, usr.SolanaWalletPublicKey, usr.PasswordHash, pq.Array(usr.Games), pq.Array(roleNames(usr.Roles)), usr.CreatedAt,
 	)
-	return err
+	return userError(err)
 }
 
 func (s *PostgresStorage) FindOne(ctx context.Context, filter user.SingleFilter) (user.User, error) {
@@ -47,47 +60,106 @@ func (s *PostgresStorage) FindOne(ctx context.Context, filter user.SingleFilter)
 	if filter.SolanaWallet != nil {
 		return s.FindOneBySolanaWallet(ctx, *filter.SolanaWallet)
 	}
-	return user.User{}, sql.ErrNoRows
+	return user.User{}, fmt.Errorf("%w: set an id, username or solana wallet", user.ErrInvalidFilter)
 }
 
 func (s *PostgresStorage) FindOneByUsername(ctx context.Context, username string) (user.User, error) {
-	var u user.User
-	query := `
-	SELECT id, username, solana_wallet_public_key, password_hash, games
-	FROM users WHERE username = $1
-	`
-	err := s.db.QueryRowContext(ctx, query, username).Scan(
-		&u.ID, &u.Username, &u.SolanaWalletPublicKey, &u.PasswordHash, pq.Array(&u.Games),
-	)
-	return u, err
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	u, err := scanUser(s.db.QueryRowContext(ctx, query, username))
+	return u, userError(err)
 }
 
 func (s *PostgresStorage) FindOneByID(ctx context.Context, id uuid.UUID) (user.User, error) {
-	var u user.User
-	query := `
-	SELECT id, username, solana_wallet_public_key, password_hash, games
-	FROM users WHERE id = $1
-	`
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&u.ID, &u.Username, &u.SolanaWalletPublicKey, &u.PasswordHash, pq.Array(&u.Games),
-	)
-	return u, err
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	u, err := scanUser(s.db.QueryRowContext(ctx, query, id))
+	return u, userError(err)
 }
 
 func (s *PostgresStorage) FindOneBySolanaWallet(ctx context.Context, solanaWallet string) (user.User, error) {
-	var u user.User
-	query := `
-	SELECT id, username, solana_wallet_public_key, password_hash, games
-	FROM users WHERE solana_wallet_public_key = $1
-	`
-	err := s.db.QueryRowContext(ctx, query, solanaWallet).Scan(
-		&u.ID, &u.Username, &u.SolanaWalletPublicKey, &u.PasswordHash, pq.Array(&u.Games),
-	)
-	return u, err
+	query := `SELECT ` + userColumns + ` FROM users WHERE solana_wallet_public_key = $1`
+	u, err := scanUser(s.db.QueryRowContext(ctx, query, solanaWallet))
+	return u, userError(err)
 }
 
 func (s *PostgresStorage) Find(ctx context.Context, filter user.Filter) ([]user.User, error) {
-	// build dynamic IN clauses
+	clauses, args := userFilterClauses(filter)
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	query := "SELECT " + userColumns + " FROM users WHERE " + strings.Join(clauses, " AND ")
+	return s.query(ctx, query, args...)
+}
+
+// Delete relies on the ON DELETE CASCADE foreign keys of every table
+// referencing users to erase the user's data in the same statement.
+func (s *PostgresStorage) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return userError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStorage) List(ctx context.Context, q user.StorageQuery) ([]user.User, int, error) {
+	clauses, args := userFilterClauses(q.Filter)
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT count(*) FROM users"+where, args...).Scan(&total); err != nil {
+		return nil, 0, userError(err)
+	}
+
+	// usernames sort bytewise so the order does not depend on the database
+	// collation; id breaks ties for a stable keyset
+	sortExpr, cast := `username COLLATE "C"`, ` COLLATE "C"`
+	if q.Sort == user.SortCreatedAt {
+		sortExpr, cast = "created_at", "::timestamptz"
+	}
+	dir, cmp := "ASC", ">"
+	if q.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	if q.After != nil {
+		clauses = append(clauses, fmt.Sprintf("(%s, id) %s ($%d%s, $%d)", sortExpr, cmp, len(args)+1, cast, len(args)+2))
+		args = append(args, q.After.Value, q.After.ID)
+	}
+	query := "SELECT " + userColumns + " FROM users"
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", sortExpr, dir, dir, len(args)+1)
+	args = append(args, q.Limit)
+	users, err := s.query(ctx, query, args...)
+	return users, total, err
+}
+
+func (s *PostgresStorage) query(ctx context.Context, query string, args ...interface{}) ([]user.User, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, userError(err)
+	}
+	defer rows.Close()
+	users := []user.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// userFilterClauses builds the AND-combined WHERE clauses for filter,
+// numbering parameters from $1.
+func userFilterClauses(filter user.Filter) ([]string, []interface{}) {
 	clauses := []string{}
 	args := []interface{}{}
 	idx := 1
@@ -106,23 +178,69 @@ func (s *PostgresStorage) Find(ctx context.Context, filter user.Filter) ([]user.
 		args = append(args, pq.Array(filter.SolanaWallets))
 		idx++
 	}
-	if len(clauses) == 0 {
-		return nil, nil
+	if filter.UsernamePrefix != "" {
+		clauses = append(clauses, fmt.Sprintf("username ILIKE $%d", idx))
+		args = append(args, escapeLike(filter.UsernamePrefix)+"%")
+		idx++
 	}
-	query := "SELECT id, username, solana_wallet_public_key, password_hash, games FROM users WHERE " + strings.Join(clauses, " AND ")
-	rows, err := s.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	if filter.UsernameContains != "" {
+		clauses = append(clauses, fmt.Sprintf("username ILIKE $%d", idx))
+		args = append(args, "%"+escapeLike(filter.UsernameContains)+"%")
+		idx++
 	}
-	defer rows.Close()
-	users := []user.User{}
-	for rows.Next() {
-		var u user.User
-		err := rows.Scan(&u.ID, &u.Username, &u.SolanaWalletPublicKey, &u.PasswordHash, pq.Array(&u.Games))
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, u)
+	if len(filter.Games) > 0 {
+		clauses = append(clauses, fmt.Sprintf("games && $%d", idx))
+		args = append(args, pq.Array(filter.Games))
+		idx++
 	}
-	return users, rows.Err()
+	return clauses, args
+}
+
+// userError maps driver errors to the user storage errors; other errors
+// are returned unchanged.
+func userError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.ErrNotFound
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == uniqueViolation && strings.Contains(pqErr.Constraint, "wallet"):
+		return user.ErrWalletTaken
+	case pqErr.Code == uniqueViolation:
+		return user.ErrUsernameTaken
+	case pqErr.Code.Class() == integrityViolation:
+		return fmt.Errorf("%w: %s", user.ErrConflict, pqErr.Message)
+	case pqErr.Code.Class() == dataException:
+		return fmt.Errorf("%w: %s", user.ErrInvalidFilter, pqErr.Message)
+	}
+	return err
+}
+
+// escapeLike makes the LIKE wildcards in s match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func scanUser(row rowScanner) (user.User, error) {
+	var (
+		u     user.User
+		roles []string
+	)
+	err := row.Scan(&u.ID, &u.Username, &u.SolanaWalletPublicKey, &u.PasswordHash, pq.Array(&u.Games), pq.Array(&roles), &u.CreatedAt)
+	u.CreatedAt = u.CreatedAt.UTC()
+	for _, r := range roles {
+		u.Roles = append(u.Roles, user.Role(r))
+	}
+	return u, err
+}
+
+func roleNames(roles []user.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		names = append(names, string(r))
+	}
+	return names
 }
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/compiai/engine/internal/core/domain/user"
+	"github.com/compiai/engine/internal/core/domain/user/usertest"
+)
+
+// postgresDSNEnv names the database the Postgres storage tests run against.
+// The tests migrate it and truncate its tables, so never point it at real data.
+const postgresDSNEnv = "COMPIAI_TEST_POSTGRES_DSN"
+
+// openTestDB connects to the database named by postgresDSNEnv and migrates
+// it, skipping the test when the variable is unset.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("set %s to run the Postgres storage tests", postgresDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := NewMigrator(slog.New(slog.NewTextHandler(io.Discard, nil)), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(t.Context()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestPostgresUserStorage(t *testing.T) {
+	db := openTestDB(t)
+	usertest.TestStorage(t, func(t *testing.T) user.Storage {
+		if _, err := db.ExecContext(t.Context(), `TRUNCATE users CASCADE`); err != nil {
+			t.Fatalf("truncate users: %v", err)
+		}
+		return NewPostgresStorage(db)
+	})
+}
@@ -0,0 +1,172 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var ErrUnsupportedKey = errors.New("unsupported key type: only RSA and Ed25519 are supported")
+
+// Key is a public key, optionally paired with its private half, identified
+// by its RFC 7638 thumbprint.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	Private crypto.Signer // nil for verification-only keys
+}
+
+// NewKey builds a Key for pub. priv may be nil; when set it must match pub.
+func NewKey(pub crypto.PublicKey, priv crypto.Signer) (Key, error) {
+	var method jwt.SigningMethod
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return Key{}, ErrUnsupportedKey
+	}
+	if priv != nil {
+		if eq, ok := priv.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !eq.Equal(pub) {
+			return Key{}, errors.New("private key does not match public key")
+		}
+	}
+	jwk, err := toJWK(pub)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{ID: jwk.thumbprint(), Method: method, Public: pub, Private: priv}, nil
+}
+
+// JWK returns the public JSON Web Key representation of k.
+func (k Key) JWK() JSONWebKey {
+	jwk, _ := toJWK(k.Public) // NewKey already rejected unsupported types
+	jwk.Kid = k.ID
+	jwk.Use = "sig"
+	jwk.Alg = k.Method.Alg()
+	return jwk
+}
+
+// JSONWebKey is a public key in RFC 7517 form.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func toJWK(pub crypto.PublicKey) (JSONWebKey, error) {
+	enc := base64.RawURLEncoding
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			N:   enc.EncodeToString(k.N.Bytes()),
+			E:   enc.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JSONWebKey{Kty: "OKP", Crv: "Ed25519", X: enc.EncodeToString(k)}, nil
+	}
+	return JSONWebKey{}, ErrUnsupportedKey
+}
+
+// thumbprint computes the RFC 7638 SHA-256 thumbprint over the required
+// members of the key, in lexicographic order.
+func (j JSONWebKey) thumbprint() string {
+	var members interface{}
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+	payload, _ := json.Marshal(members)
+	sum := sha256.Sum256(payload)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// LoadPrivateKey reads a PEM encoded PKCS#8 or PKCS#1 private key from a
+// file:// URL.
+func LoadPrivateKey(rawURL string) (crypto.Signer, error) {
+	block, err := readPEM(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, ErrUnsupportedKey
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("parse private key %s: not a PKCS#8 or PKCS#1 key", rawURL)
+}
+
+// LoadPublicKey reads a PEM encoded PKIX public key from a file:// URL.
+func LoadPublicKey(rawURL string) (crypto.PublicKey, error) {
+	block, err := readPEM(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key %s: %w", rawURL, err)
+	}
+	return key, nil
+}
+
+func readPEM(rawURL string) (*pem.Block, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse key url: %w", err)
+	}
+	if u.Scheme != "file" {
+		return nil, fmt.Errorf("unsupported key url scheme %q: expected file://", u.Scheme)
+	}
+	// file://path/key.pem is relative, file:///etc/key.pem is absolute
+	data, err := os.ReadFile(u.Host + u.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("read key %s: no PEM data", rawURL)
+	}
+	return block, nil
+}
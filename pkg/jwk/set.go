@@ -0,0 +1,63 @@
+package jwk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Set signs tokens with one key and verifies them against every known key,
+// so tokens issued before a key rotation stay valid until they expire.
+type Set struct {
+	signing Key
+	keys    map[string]Key
+	order   []string
+}
+
+// NewSet creates a Set signing with signing, which must carry a private key.
+// verification keys are accepted for verification only.
+func NewSet(signing Key, verification ...Key) (*Set, error) {
+	if signing.Private == nil {
+		return nil, errors.New("signing key has no private key")
+	}
+	s := &Set{signing: signing, keys: make(map[string]Key, len(verification)+1)}
+	for _, k := range append([]Key{signing}, verification...) {
+		if _, dup := s.keys[k.ID]; dup {
+			continue
+		}
+		s.keys[k.ID] = k
+		s.order = append(s.order, k.ID)
+	}
+	return s, nil
+}
+
+// Sign signs claims with the signing key and sets the kid header.
+func (s *Set) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.Private)
+}
+
+// Keyfunc resolves the verification key named by the token's kid header and
+// rejects tokens whose alg does not match that key.
+func (s *Set) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %q", token.Header["alg"], kid)
+	}
+	return key.Public, nil
+}
+
+// JWKS returns the public half of every key in the set.
+func (s *Set) JWKS() JSONWebKeySet {
+	out := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(s.order))}
+	for _, id := range s.order {
+		out.Keys = append(out.Keys, s.keys[id].JWK())
+	}
+	return out
+}
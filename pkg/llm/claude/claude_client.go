@@ -5,113 +5,213 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/compiai/engine/pkg/llm"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 )
 
+// DefaultAPIVersion is sent as the anthropic-version header when Config.APIVersion is empty.
+const DefaultAPIVersion = "2023-06-01"
+
 type Config struct {
 	ApiKey            string  `yaml:"apiKey"`
-	Endpoint          string  `yaml:"endpoint"`
+	Endpoint          string  `yaml:"endpoint"` // API base URL, e.g. https://api.anthropic.com/v1
+	APIVersion        string  `yaml:"apiVersion"`
 	Model             string  `yaml:"model"`
 	Temperature       float64 `yaml:"temperature"`
 	MaxTokensToSample int     `yaml:"maxTokensToSample"`
+
+	Timeouts llm.Timeouts    `yaml:"timeouts"`
+	Retry    llm.RetryPolicy `yaml:"retry"`
+}
+
+// Validate reports whether the config is complete enough to call the API.
+func (c Config) Validate() error {
+	var errs []error
+	if c.ApiKey == "" {
+		errs = append(errs, errors.New("apiKey is required"))
+	}
+	if u, err := url.Parse(c.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("endpoint %q is not an absolute URL", c.Endpoint))
+	}
+	if c.Model == "" {
+		errs = append(errs, errors.New("model is required"))
+	}
+	if c.Temperature < 0 || c.Temperature > 1 {
+		errs = append(errs, errors.New("temperature must be between 0 and 1"))
+	}
+	if c.MaxTokensToSample <= 0 {
+		errs = append(errs, errors.New("maxTokensToSample must be positive"))
+	}
+	return errors.Join(errs...)
 }
 
-// ClaudeClient implements llm.Streamer using Anthropic's Claude API.
+// ClaudeClient implements llm.Streamer using Anthropic's Messages API.
 type ClaudeClient struct {
+	logger     *slog.Logger
 	config     Config
 	httpClient *http.Client
 }
 
 // NewClaudeClient creates a new Claude client using provided Config.
-func NewClaudeClient(cfg Config) *ClaudeClient {
-	return &ClaudeClient{config: cfg, httpClient: http.DefaultClient}
+func NewClaudeClient(logger *slog.Logger, cfg Config) *ClaudeClient {
+	return &ClaudeClient{
+		logger:     logger.WithGroup("claude-client"),
+		config:     cfg,
+		httpClient: llm.NewHTTPClient(cfg.Timeouts),
+	}
 }
 
 func (c *ClaudeClient) Stream(ctx context.Context, request llm.GenerateRequest) (<-chan llm.GenerateStreamResponse, error) {
-
-	// Build the Anthropic prompt
-	var sb strings.Builder
-	if request.Prompt.System != "" {
-		sb.WriteString("\n\nHuman: ")
-		sb.WriteString(request.Prompt.System)
-	}
+	// Build the messages sequence: history turns, then the new user prompt.
+	// The system prompt goes into its own top-level field.
+	msgs := make([]message, 0, 2*len(request.History)+1)
 	for _, conv := range request.History {
-		sb.WriteString("\n\nHuman: ")
-		sb.WriteString(conv.Request)
-		sb.WriteString("\n\nAssistant: ")
-		sb.WriteString(conv.Response)
+		msgs = append(msgs, message{Role: "user", Content: conv.Request})
+		msgs = append(msgs, message{Role: "assistant", Content: conv.Response})
 	}
-	sb.WriteString("\n\nHuman: ")
-	sb.WriteString(request.Prompt.User)
-	sb.WriteString("\n\nAssistant:")
+	msgs = append(msgs, message{Role: "user", Content: request.Prompt.User})
 
 	reqBody := apiRequest{
-		Model:             c.config.Model,
-		Prompt:            sb.String(),
-		MaxTokensToSample: c.config.MaxTokensToSample,
-		Temperature:       c.config.Temperature,
-		Stream:            true,
+		Model:       c.config.Model,
+		System:      request.Prompt.System,
+		Messages:    msgs,
+		MaxTokens:   c.config.MaxTokensToSample,
+		Temperature: c.config.Temperature,
+		Stream:      true,
 	}
 	data, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("Claude marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
-		strings.TrimRight(c.config.Endpoint, "/"), bytes.NewReader(data),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("Claude new request: %w", err)
+	messagesEndpoint := strings.TrimRight(c.config.Endpoint, "/") + "/messages"
+	apiVersion := c.config.APIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("X-API-Key", c.config.ApiKey)
+	timeouts := c.config.Timeouts.WithDefaults()
 
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("Claude do request: %w", err)
+	// Bound the whole call; cancel is handed to the reading goroutine on success.
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Total)
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, messagesEndpoint, bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("Claude new request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Accept", "text/event-stream")
+		httpReq.Header.Set("x-api-key", c.config.ApiKey)
+		httpReq.Header.Set("anthropic-version", apiVersion)
+		return httpReq, nil
 	}
-	if resp.StatusCode != http.StatusOK {
+	toError := func(resp *http.Response) error {
 		defer resp.Body.Close()
 		errBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Claude error response: %s", string(errBody))
+		msg := string(errBody)
+		var apiErr errorResponse
+		if json.Unmarshal(errBody, &apiErr) == nil && apiErr.Error.Message != "" {
+			msg = apiErr.Error.Type + ": " + apiErr.Error.Message
+		}
+		return llm.NewStatusError(resp.StatusCode, resp.Header, msg)
+	}
+	resp, err := llm.Do(ctx, c.logger, c.httpClient, c.config.Retry, newRequest, toError)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("Claude request: %w", err)
 	}
 
 	ch := make(chan llm.GenerateStreamResponse)
 	go func() {
 		defer close(ch)
+		defer cancel()
+		defer resp.Body.Close()
+		firstToken := llm.StartFirstTokenTimer(timeouts.FirstToken, cancel)
+		defer firstToken.Stop()
 		reader := bufio.NewReader(resp.Body)
+		var messageID string
+		usage := llm.Usage{Provider: "claude", Model: c.config.Model}
 		for {
 			line, err := reader.ReadString('\n')
 			if err != nil {
-				if err != io.EOF {
-					ch <- llm.GenerateStreamResponse{Error: err}
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF // the stream must end with message_stop
 				}
+				ch <- llm.GenerateStreamResponse{ID: messageID, Error: fmt.Errorf("Claude stream: %w", firstToken.Err(err))}
 				return
 			}
+			// "event:" lines duplicate the payload's type field, so only data lines matter
 			line = strings.TrimSpace(line)
 			if !strings.HasPrefix(line, "data:") {
 				continue
 			}
-			payload := strings.TrimPrefix(line, "data: ")
-			if payload == "[DONE]" {
-				return
-			}
-			var event struct {
-				Completion string `json:"completion"`
-			}
+			payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+			var event streamEvent
 			if err := json.Unmarshal([]byte(payload), &event); err != nil {
-				ch <- llm.GenerateStreamResponse{Error: fmt.Errorf("Claude invalid stream: %w", err)}
+				ch <- llm.GenerateStreamResponse{ID: messageID, Error: fmt.Errorf("Claude invalid stream: %w", err)}
 				return
 			}
-			if event.Completion != "" {
-				ch <- llm.GenerateStreamResponse{Response: event.Completion}
+			switch event.Type {
+			case eventMessageStart:
+				if event.Message != nil {
+					messageID = event.Message.ID
+					usage.Model = event.Message.Model
+					u := event.Message.Usage
+					usage.PromptTokens = u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+					usage.CompletionTokens = u.OutputTokens
+				}
+			case eventContentBlockDelta:
+				if event.Delta != nil && event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+					firstToken.Stop()
+					ch <- llm.GenerateStreamResponse{ID: messageID, Response: event.Delta.Text}
+				}
+			case eventMessageDelta:
+				if event.Usage != nil {
+					usage.CompletionTokens = event.Usage.OutputTokens
+				}
+			case eventMessageStop:
+				usage.Latency = time.Since(start)
+				ch <- llm.GenerateStreamResponse{ID: messageID, Usage: &usage}
+				return
+			case eventError:
+				apiErr := apiError{Type: "unknown_error"}
+				if event.Error != nil {
+					apiErr = *event.Error
+				}
+				ch <- llm.GenerateStreamResponse{ID: messageID, Error: fmt.Errorf("Claude stream error: %w", streamErrorStatus(apiErr))}
+				return
+			case eventContentBlockStart, eventContentBlockStop, eventPing:
+				// no text to emit
 			}
 		}
 	}()
 
 	return ch, nil
 }
+
+// streamErrorStatus maps an in-stream error event to the HTTP status the same
+// error type would have produced, so callers can classify it uniformly.
+func streamErrorStatus(apiErr apiError) *llm.StatusError {
+	status := http.StatusInternalServerError
+	switch apiErr.Type {
+	case "overloaded_error":
+		status = llm.StatusOverloaded
+	case "rate_limit_error":
+		status = http.StatusTooManyRequests
+	case "invalid_request_error":
+		status = http.StatusBadRequest
+	case "authentication_error":
+		status = http.StatusUnauthorized
+	case "permission_error":
+		status = http.StatusForbidden
+	}
+	return llm.NewStatusError(status, nil, apiErr.Type+": "+apiErr.Message)
+}
@@ -1,9 +1,72 @@
 package claude
 
+// Messages API payloads
+// https://docs.anthropic.com/en/api/messages
+
 type apiRequest struct {
-	Model             string  `json:"model"`
-	Prompt            string  `json:"prompt"`
-	MaxTokensToSample int     `json:"max_tokens_to_sample"`
-	Temperature       float64 `json:"temperature"`
-	Stream            bool    `json:"stream"`
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature float64   `json:"temperature"`
+	Stream      bool      `json:"stream"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Streaming event types
+// https://docs.anthropic.com/en/api/messages-streaming
+const (
+	eventMessageStart      = "message_start"
+	eventContentBlockStart = "content_block_start"
+	eventContentBlockDelta = "content_block_delta"
+	eventContentBlockStop  = "content_block_stop"
+	eventMessageDelta      = "message_delta"
+	eventMessageStop       = "message_stop"
+	eventPing              = "ping"
+	eventError             = "error"
+)
+
+// streamEvent is the union of all streaming event payloads; which fields are
+// set depends on Type.
+type streamEvent struct {
+	Type    string         `json:"type"`
+	Message *streamMessage `json:"message,omitempty"`
+	Index   int            `json:"index"`
+	Delta   *streamDelta   `json:"delta,omitempty"`
+	Usage   *usage         `json:"usage,omitempty"` // cumulative output tokens on message_delta
+	Error   *apiError      `json:"error,omitempty"`
+}
+
+type streamMessage struct {
+	ID    string `json:"id"`
+	Model string `json:"model"`
+	Usage usage  `json:"usage"`
+}
+
+type usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type streamDelta struct {
+	Type       string `json:"type"`
+	Text       string `json:"text,omitempty"`
+	StopReason string `json:"stop_reason,omitempty"`
+}
+
+type apiError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// errorResponse is the body of non-200 responses.
+type errorResponse struct {
+	Type  string   `json:"type"`
+	Error apiError `json:"error"`
 }
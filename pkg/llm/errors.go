@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Typed errors callers can match with errors.Is. A *StatusError unwraps to
+// the one matching its status code.
+var (
+	ErrRateLimited       = errors.New("LLM provider rate limit exceeded")
+	ErrContextLength     = errors.New("LLM prompt exceeds the model context length")
+	ErrAuth              = errors.New("LLM provider rejected credentials")
+	ErrOverloaded        = errors.New("LLM provider overloaded")
+	ErrUnavailable       = errors.New("LLM provider unavailable")
+	ErrFirstTokenTimeout = errors.New("LLM provider sent no output before the first-token timeout")
+	ErrBadRequest        = errors.New("LLM provider rejected the request")
+)
+
+// StatusOverloaded is the non-standard status Anthropic uses when overloaded.
+const StatusOverloaded = 529
+
+// StatusError is returned by clients when a provider answers with a non-200 status.
+type StatusError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // parsed Retry-After header, zero if absent
+}
+
+// NewStatusError builds a StatusError, parsing Retry-After from header when present.
+func NewStatusError(statusCode int, header http.Header, message string) *StatusError {
+	return &StatusError{
+		StatusCode: statusCode,
+		Message:    message,
+		RetryAfter: parseRetryAfter(header, time.Now()),
+	}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	case e.StatusCode == StatusOverloaded:
+		return ErrOverloaded
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrUnavailable
+	case isContextLengthMessage(e.Message):
+		return ErrContextLength
+	case e.StatusCode >= http.StatusBadRequest:
+		return ErrBadRequest
+	}
+	return nil
+}
+
+// retryable reports whether the status is worth retrying.
+func (e *StatusError) retryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, StatusOverloaded:
+		return true
+	}
+	return false
+}
+
+// IsRetryable reports whether err is transient and the request may succeed
+// when repeated or sent to another provider: rate limiting, server-side
+// failures, first-token timeouts and network errors. Cancellation by the
+// caller is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrFirstTokenTimeout) {
+		return true
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isContextLengthMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "context_length_exceeded") || // OpenAI error code
+		strings.Contains(msg, "maximum context length") ||
+		strings.Contains(msg, "prompt is too long") // Anthropic
+}
+
+// parseRetryAfter reads the Retry-After header in either delay-seconds or
+// HTTP-date form.
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	v := strings.TrimSpace(header.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if at, err := http.ParseTime(v); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// NamedStreamer pairs a Streamer with the provider name reported in responses.
+type NamedStreamer struct {
+	Name     string
+	Streamer Streamer
+}
+
+// Fallback is a Streamer that tries providers in priority order. It fails
+// over to the next provider on retryable errors, but only until the first
+// token has been received: once output has been streamed it is never mixed
+// with output from another provider.
+type Fallback struct {
+	logger    *slog.Logger
+	providers []NamedStreamer
+}
+
+func NewFallback(logger *slog.Logger, providers ...NamedStreamer) *Fallback {
+	return &Fallback{
+		logger:    logger.WithGroup("llm-fallback"),
+		providers: providers,
+	}
+}
+
+func (f *Fallback) Stream(ctx context.Context, request GenerateRequest) (<-chan GenerateStreamResponse, error) {
+	if len(f.providers) == 0 {
+		return nil, errors.New("no LLM providers configured")
+	}
+
+	var lastErr error
+	for i, p := range f.providers {
+		last := i == len(f.providers)-1
+
+		stream, err := p.Streamer.Stream(ctx, request)
+		if err != nil {
+			lastErr = err
+			if !last && IsRetryable(err) && ctx.Err() == nil {
+				f.logger.Warn("provider failed, failing over", "provider", p.Name, "next", f.providers[i+1].Name, "err", err)
+				continue
+			}
+			return nil, err
+		}
+
+		// Wait for the first message so errors before any output can still fail over.
+		first, open := <-stream
+		if open && first.Error != nil && first.Response == "" && !last && IsRetryable(first.Error) && ctx.Err() == nil {
+			lastErr = first.Error
+			f.logger.Warn("provider stream failed, failing over", "provider", p.Name, "next", f.providers[i+1].Name, "err", first.Error)
+			go drain(stream)
+			continue
+		}
+
+		f.logger.Info("LLM request served", "provider", p.Name, "attempt", i+1)
+		out := make(chan GenerateStreamResponse)
+		go func() {
+			defer close(out)
+			if !open {
+				return
+			}
+			first.Provider = p.Name
+			out <- first
+			for msg := range stream {
+				msg.Provider = p.Name
+				out <- msg
+			}
+		}()
+		return out, nil
+	}
+	return nil, lastErr
+}
+
+func drain(stream <-chan GenerateStreamResponse) {
+	for range stream {
+	}
+}
@@ -10,6 +10,8 @@ type GenerateRequest struct {
 type GenerateStreamResponse struct {
 	ID       string `json:"id"`
 	Response string `json:"response"`
+	Provider string `json:"provider,omitempty"` // set by Fallback to the provider that served the request
+	Usage    *Usage `json:"usage,omitempty"`    // only set on the final record of a stream
 	Error    error  // if no error this should be null/nil
 }
 
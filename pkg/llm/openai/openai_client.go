@@ -5,18 +5,47 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/compiai/engine/pkg/llm"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	ApiKey   string `yaml:"apiKey"`
-	Endpoint string `yaml:"endpoint"`
-	Model    string `yaml:"model"`
+	ApiKey            string  `yaml:"apiKey"`
+	Endpoint          string  `yaml:"endpoint"`
+	Model             string  `yaml:"model"`
+	Temperature       float64 `yaml:"temperature"`
+	MaxTokensToSample int     `yaml:"maxTokensToSample"` // 0 leaves the limit to the API
+
+	Timeouts llm.Timeouts    `yaml:"timeouts"`
+	Retry    llm.RetryPolicy `yaml:"retry"`
+}
+
+// Validate reports whether the config is complete enough to call the API.
+func (c Config) Validate() error {
+	var errs []error
+	if c.ApiKey == "" {
+		errs = append(errs, errors.New("apiKey is required"))
+	}
+	if u, err := url.Parse(c.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("endpoint %q is not an absolute URL", c.Endpoint))
+	}
+	if c.Model == "" {
+		errs = append(errs, errors.New("model is required"))
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		errs = append(errs, errors.New("temperature must be between 0 and 2"))
+	}
+	if c.MaxTokensToSample < 0 {
+		errs = append(errs, errors.New("maxTokensToSample must not be negative"))
+	}
+	return errors.Join(errs...)
 }
 
 // Client implements the llm.Streamer interface using OpenAI's Chat Completions API.
@@ -26,12 +55,12 @@ type Client struct {
 	httpClient *http.Client
 }
 
-// NewClient creates a new OpenAI client. Uses gpt-3.5-turbo by default.
+// NewClient creates a new OpenAI client.
 func NewClient(logger *slog.Logger, config Config) *Client {
 	return &Client{
 		logger:     logger.WithGroup("openai-client"),
 		config:     config,
-		httpClient: http.DefaultClient,
+		httpClient: llm.NewHTTPClient(config.Timeouts),
 	}
 }
 
@@ -42,10 +71,16 @@ func (c *Client) Stream(ctx context.Context, request llm.GenerateRequest) (<-cha
 		Role    string `json:"role"`
 		Content string `json:"content"`
 	}
+	type streamOptions struct {
+		IncludeUsage bool `json:"include_usage"`
+	}
 	type streamRequest struct {
-		Model    string        `json:"model"`
-		Messages []chatMessage `json:"messages"`
-		Stream   bool          `json:"stream"`
+		Model         string        `json:"model"`
+		Messages      []chatMessage `json:"messages"`
+		Temperature   float64       `json:"temperature"`
+		MaxTokens     int           `json:"max_tokens,omitempty"`
+		Stream        bool          `json:"stream"`
+		StreamOptions streamOptions `json:"stream_options"`
 	}
 
 	// Build the messages sequence: system, history, then new user prompt
@@ -56,42 +91,62 @@ func (c *Client) Stream(ctx context.Context, request llm.GenerateRequest) (<-cha
 	}
 	msgs = append(msgs, chatMessage{Role: "user", Content: request.Prompt.User})
 
-	reqBody := streamRequest{Model: c.config.Model, Messages: msgs, Stream: true}
+	reqBody := streamRequest{
+		Model:         c.config.Model,
+		Messages:      msgs,
+		Temperature:   c.config.Temperature,
+		MaxTokens:     c.config.MaxTokensToSample,
+		Stream:        true,
+		StreamOptions: streamOptions{IncludeUsage: true},
+	}
 	data, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	completionsEndpoint := fmt.Sprintf("%s/chat/completions", c.config.Endpoint)
+	timeouts := c.config.Timeouts.WithDefaults()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", completionsEndpoint, bytes.NewReader(data))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.config.ApiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("stream request error: %w", err)
+	// Bound the whole call; cancel is handed to the reading goroutine on success.
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Total)
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", completionsEndpoint, bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+c.config.ApiKey)
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq, nil
 	}
-	if resp.StatusCode != http.StatusOK {
+	toError := func(resp *http.Response) error {
 		defer resp.Body.Close()
 		errBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("stream request failed: %s", string(errBody))
+		return llm.NewStatusError(resp.StatusCode, resp.Header, string(errBody))
+	}
+	resp, err := llm.Do(ctx, c.logger, c.httpClient, c.config.Retry, newRequest, toError)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("stream request failed: %w", err)
 	}
 
 	ch := make(chan llm.GenerateStreamResponse)
 	// Start goroutine to read the SSE stream
 	go func() {
 		defer close(ch)
+		defer cancel()
+		defer resp.Body.Close()
+		firstToken := llm.StartFirstTokenTimer(timeouts.FirstToken, cancel)
+		defer firstToken.Stop()
 		reader := bufio.NewReader(resp.Body)
+		var usage *llm.Usage
 		for {
 			line, err := reader.ReadString('\n')
 			if err != nil {
-				if err != io.EOF {
-					ch <- llm.GenerateStreamResponse{Error: err}
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF // the stream must end with [DONE]
 				}
+				ch <- llm.GenerateStreamResponse{Error: firstToken.Err(err)}
 				return
 			}
 			line = strings.TrimSpace(line)
@@ -100,16 +155,22 @@ func (c *Client) Stream(ctx context.Context, request llm.GenerateRequest) (<-cha
 			}
 			payload := strings.TrimPrefix(line, "data: ")
 			if payload == "[DONE]" {
+				if usage != nil {
+					usage.Latency = time.Since(start)
+					ch <- llm.GenerateStreamResponse{Usage: usage}
+				}
 				return
 			}
 			// Parse the streamed JSON chunk
 			var event struct {
 				ID      string `json:"id"`
+				Model   string `json:"model"`
 				Choices []struct {
 					Delta struct {
 						Content string `json:"content"`
 					} `json:"delta"`
 				} `json:"choices"`
+				Usage *Usage `json:"usage"` // only on the final chunk when include_usage is set
 			}
 			if err := json.Unmarshal([]byte(payload), &event); err != nil {
 				ch <- llm.GenerateStreamResponse{Error: fmt.Errorf("invalid stream response: %w", err)}
@@ -119,9 +180,18 @@ func (c *Client) Stream(ctx context.Context, request llm.GenerateRequest) (<-cha
 			// Emit each non-empty content delta
 			for _, choice := range event.Choices {
 				if choice.Delta.Content != "" {
+					firstToken.Stop()
 					ch <- llm.GenerateStreamResponse{ID: event.ID, Response: choice.Delta.Content}
 				}
 			}
+			if event.Usage != nil {
+				usage = &llm.Usage{
+					Provider:         "openai",
+					Model:            event.Model,
+					PromptTokens:     event.Usage.PromptTokens,
+					CompletionTokens: event.Usage.CompletionTokens,
+				}
+			}
 		}
 	}()
 
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrUnknownProvider = errors.New("unknown LLM provider")
+
+// Factory builds a Streamer for a provider. It should validate the
+// provider's configuration and fail if it is incomplete.
+type Factory func() (Streamer, error)
+
+// Registry maps provider names (as used in config) to factories.
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds or replaces the factory for name.
+func (r *Registry) Register(name string, factory Factory) {
+	r.factories[name] = factory
+}
+
+// New constructs the Streamer registered under name.
+func (r *Registry) New(name string) (Streamer, error) {
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (available: %v)", ErrUnknownProvider, name, r.Providers())
+	}
+	streamer, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("provider %q: %w", name, err)
+	}
+	return streamer, nil
+}
+
+// Providers lists registered provider names in sorted order.
+func (r *Registry) Providers() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
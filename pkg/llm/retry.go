@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures retries of failed provider requests.
+type RetryPolicy struct {
+	MaxAttempts    int           `yaml:"maxAttempts"` // including the first attempt
+	InitialBackoff time.Duration `yaml:"initialBackoff"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`
+}
+
+// WithDefaults fills unset fields with conservative defaults.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 500 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 8 * time.Second
+	}
+	return p
+}
+
+// backoff returns the wait before retry number attempt (1-based), using full
+// jitter over an exponentially growing window. A server-provided Retry-After
+// takes precedence.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	window := p.InitialBackoff << (attempt - 1)
+	if window <= 0 || window > p.MaxBackoff {
+		window = p.MaxBackoff
+	}
+	return time.Duration(rand.Int64N(int64(window) + 1))
+}
+
+// Do sends the request built by newRequest, retrying network errors and
+// retryable statuses according to policy. toError converts a non-200
+// response into an error (usually a *StatusError) and must close its body.
+// On success the caller owns the returned response body.
+func Do(
+	ctx context.Context,
+	logger *slog.Logger,
+	client *http.Client,
+	policy RetryPolicy,
+	newRequest func(ctx context.Context) (*http.Request, error),
+	toError func(resp *http.Response) error,
+) (*http.Response, error) {
+	policy = policy.WithDefaults()
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		if err == nil {
+			err = toError(resp)
+		}
+		if attempt >= policy.MaxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			retryAfter = statusErr.RetryAfter
+		}
+		wait := policy.backoff(attempt, retryAfter)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, fmt.Errorf("retry in %s exceeds deadline: %w", wait, err)
+		}
+		logger.Warn("LLM request failed, retrying", "attempt", attempt, "wait", wait, "err", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Timeouts bounds the phases of a streaming provider call.
+type Timeouts struct {
+	// Connect bounds dialing and the TLS handshake.
+	Connect time.Duration `yaml:"connect"`
+	// FirstToken bounds the wait for response headers and, once the stream
+	// is open, for its first content token.
+	FirstToken time.Duration `yaml:"firstToken"`
+	// Total bounds the whole call, retries and streaming included.
+	Total time.Duration `yaml:"total"`
+}
+
+// WithDefaults fills unset fields with defaults.
+func (t Timeouts) WithDefaults() Timeouts {
+	if t.Connect <= 0 {
+		t.Connect = 5 * time.Second
+	}
+	if t.FirstToken <= 0 {
+		t.FirstToken = 15 * time.Second
+	}
+	if t.Total <= 0 {
+		t.Total = 2 * time.Minute
+	}
+	return t
+}
+
+// NewHTTPClient returns a client suited for long-lived streaming responses.
+// It has no overall Timeout; callers bound calls with a context instead.
+func NewHTTPClient(t Timeouts) *http.Client {
+	t = t.WithDefaults()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: t.Connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = t.Connect
+	transport.ResponseHeaderTimeout = t.FirstToken
+	return &http.Client{Transport: transport}
+}
+
+// FirstTokenTimer cancels a stream that produces no output in time.
+type FirstTokenTimer struct {
+	timer *time.Timer
+	fired atomic.Bool
+}
+
+// StartFirstTokenTimer calls cancel unless Stop is called within d.
+func StartFirstTokenTimer(d time.Duration, cancel context.CancelFunc) *FirstTokenTimer {
+	t := &FirstTokenTimer{}
+	t.timer = time.AfterFunc(d, func() {
+		t.fired.Store(true)
+		cancel()
+	})
+	return t
+}
+
+// Stop disarms the timer; call it when the first token arrives.
+func (t *FirstTokenTimer) Stop() {
+	t.timer.Stop()
+}
+
+// Err converts a stream read error into ErrFirstTokenTimeout when the timer
+// caused it.
+func (t *FirstTokenTimer) Err(err error) error {
+	if t.fired.Load() {
+		return fmt.Errorf("%w (after %v)", ErrFirstTokenTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"strings"
+	"time"
+)
+
+// Usage is the token accounting of one provider call. Streamers send it as
+// the last record of a stream, with an empty Response.
+type Usage struct {
+	Provider         string        `json:"provider"`
+	Model            string        `json:"model"`
+	PromptTokens     int           `json:"promptTokens"`
+	CompletionTokens int           `json:"completionTokens"`
+	Latency          time.Duration `json:"latency"` // from request start to the end of the stream
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	PromptPerMillion     float64 `yaml:"promptPerMillion"`
+	CompletionPerMillion float64 `yaml:"completionPerMillion"`
+}
+
+// PriceTable maps model names to prices.
+type PriceTable map[string]Price
+
+// Cost returns the USD cost of u. Providers often report dated snapshot names
+// (e.g. "gpt-4o-2024-08-06"), so when there is no exact entry the longest
+// configured model name that prefixes u.Model is used. ok is false when no
+// price matches.
+func (t PriceTable) Cost(u Usage) (cost float64, ok bool) {
+	p, ok := t[u.Model]
+	if !ok {
+		best := ""
+		for model, price := range t {
+			if strings.HasPrefix(u.Model, model) && len(model) > len(best) {
+				best, p = model, price
+			}
+		}
+		if best == "" {
+			return 0, false
+		}
+	}
+	return (float64(u.PromptTokens)*p.PromptPerMillion + float64(u.CompletionTokens)*p.CompletionPerMillion) / 1e6, true
+}